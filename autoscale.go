@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// AUTOSCALING WORKER POOL: Elastic concurrency driven by queue depth
+// ============================================================================
+
+// PoolConfig bounds and tunes an AutoscalingPool
+type PoolConfig struct {
+	MinWorkers int // always running (at least 1)
+	MaxWorkers int // never more than this
+
+	// Scale up when a newly queued job would wait longer than this,
+	// estimated as queue depth × recent latency ÷ workers
+	TargetQueueDelay time.Duration
+
+	// Scale down by one worker after the queue has been empty this long
+	ScaleDownAfter time.Duration
+
+	// How often the controller looks at the queue
+	Interval time.Duration
+
+	// How many jobs are waiting (optional). By default it is len(jobs); set
+	// it when jobs reach the pool through an unbuffered channel, e.g. from a
+	// dispatcher that holds the queue itself.
+	QueueDepth func() int
+
+	// Called for every scale-up or scale-down (optional)
+	OnScale func(ScaleEvent)
+}
+
+// ScaleEvent reports a change in the number of workers
+type ScaleEvent struct {
+	Time       time.Time
+	From, To   int
+	QueueDepth int
+	Latency    time.Duration
+	Reason     string
+}
+
+func (e ScaleEvent) String() string {
+	arrow := "📈 scale up"
+	if e.To < e.From {
+		arrow = "📉 scale down"
+	}
+	return fmt.Sprintf("%s %d → %d workers (queue %d, latency %v): %s",
+		arrow, e.From, e.To, e.QueueDepth, e.Latency.Round(time.Millisecond), e.Reason)
+}
+
+// AutoscalingPool runs handle for every job on jobs with between MinWorkers
+// and MaxWorkers goroutines. jobs must be buffered - its length is the queue
+// depth the controller scales on - unless PoolConfig.QueueDepth says otherwise.
+type AutoscalingPool[T any] struct {
+	cfg    PoolConfig
+	jobs   <-chan T
+	handle func(workerID int, job T)
+
+	stop           chan struct{} // an idle worker receiving from here exits
+	controllerDone chan struct{}
+	wg             sync.WaitGroup
+
+	mu        sync.Mutex
+	workers   int
+	peak      int
+	nextID    int
+	latency   time.Duration // moving average of handle durations
+	drained   bool          // a worker saw jobs closed
+	idleSince time.Time
+	events    []ScaleEvent
+}
+
+// NewAutoscalingPool creates a pool; call Start to launch it
+func NewAutoscalingPool[T any](cfg PoolConfig, jobs <-chan T, handle func(workerID int, job T)) *AutoscalingPool[T] {
+	if cfg.MinWorkers < 1 {
+		cfg.MinWorkers = 1
+	}
+	if cfg.MaxWorkers < cfg.MinWorkers {
+		cfg.MaxWorkers = cfg.MinWorkers
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 50 * time.Millisecond
+	}
+	if cfg.QueueDepth == nil {
+		cfg.QueueDepth = func() int { return len(jobs) }
+	}
+	return &AutoscalingPool[T]{
+		cfg:            cfg,
+		jobs:           jobs,
+		handle:         handle,
+		stop:           make(chan struct{}),
+		controllerDone: make(chan struct{}),
+	}
+}
+
+// Start launches MinWorkers workers and the scaling controller
+func (p *AutoscalingPool[T]) Start() {
+	p.mu.Lock()
+	for i := 0; i < p.cfg.MinWorkers; i++ {
+		p.spawnLocked()
+	}
+	p.mu.Unlock()
+
+	go p.controller()
+}
+
+// Wait blocks until jobs is closed and every job has been handled
+func (p *AutoscalingPool[T]) Wait() {
+	<-p.controllerDone
+	p.wg.Wait()
+}
+
+// Events returns every scale event so far
+func (p *AutoscalingPool[T]) Events() []ScaleEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ScaleEvent(nil), p.events...)
+}
+
+// Peak returns the largest number of workers that ran at once
+func (p *AutoscalingPool[T]) Peak() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.peak
+}
+
+// spawnLocked starts one worker; p.mu must be held
+func (p *AutoscalingPool[T]) spawnLocked() {
+	p.nextID++
+	p.workers++
+	if p.workers > p.peak {
+		p.peak = p.workers
+	}
+	p.wg.Add(1)
+	go p.worker(p.nextID)
+}
+
+func (p *AutoscalingPool[T]) worker(id int) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.stop:
+			p.mu.Lock()
+			p.workers--
+			p.mu.Unlock()
+			return
+		case job, ok := <-p.jobs:
+			if !ok {
+				p.mu.Lock()
+				p.workers--
+				p.drained = true
+				p.mu.Unlock()
+				return
+			}
+
+			start := time.Now()
+			p.handle(id, job)
+			elapsed := time.Since(start)
+
+			// Exponential moving average: recent jobs count the most
+			p.mu.Lock()
+			if p.latency == 0 {
+				p.latency = elapsed
+			} else {
+				p.latency = (p.latency*4 + elapsed) / 5
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// controller periodically compares the queue with the workers and resizes
+func (p *AutoscalingPool[T]) controller() {
+	defer close(p.controllerDone)
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.mu.Lock()
+		if p.drained {
+			p.mu.Unlock()
+			return
+		}
+
+		depth := p.cfg.QueueDepth()
+		workers := p.workers
+		latency := p.latency
+
+		switch {
+		case depth > 0:
+			p.idleSince = time.Time{}
+
+			// How many workers would keep the queue delay on target?
+			want := workers + 1
+			if latency > 0 && p.cfg.TargetQueueDelay > 0 {
+				backlog := time.Duration(depth) * latency
+				want = int((backlog + p.cfg.TargetQueueDelay - 1) / p.cfg.TargetQueueDelay)
+				if backlog/time.Duration(workers) <= p.cfg.TargetQueueDelay {
+					want = workers
+				}
+			}
+			if want > p.cfg.MaxWorkers {
+				want = p.cfg.MaxWorkers
+			}
+			if want > workers {
+				for i := workers; i < want; i++ {
+					p.spawnLocked()
+				}
+				p.recordLocked(ScaleEvent{
+					Time: time.Now(), From: workers, To: want,
+					QueueDepth: depth, Latency: latency,
+					Reason: "queue delay above target",
+				})
+			}
+			p.mu.Unlock()
+
+		case workers > p.cfg.MinWorkers:
+			if p.idleSince.IsZero() {
+				p.idleSince = time.Now()
+			}
+			idleFor := time.Since(p.idleSince)
+			p.mu.Unlock()
+
+			if idleFor < p.cfg.ScaleDownAfter {
+				continue
+			}
+			// Only an idle worker can take the stop signal, so this never
+			// interrupts a job; if everyone is busy we try again next tick
+			select {
+			case p.stop <- struct{}{}:
+				p.mu.Lock()
+				p.idleSince = time.Now()
+				p.recordLocked(ScaleEvent{
+					Time: time.Now(), From: workers, To: workers - 1,
+					QueueDepth: depth, Latency: latency,
+					Reason: fmt.Sprintf("queue empty for %v", idleFor.Round(time.Millisecond)),
+				})
+				p.mu.Unlock()
+			default:
+			}
+
+		default:
+			p.mu.Unlock()
+		}
+	}
+}
+
+// recordLocked stores and reports a scale event; p.mu must be held
+func (p *AutoscalingPool[T]) recordLocked(e ScaleEvent) {
+	p.events = append(p.events, e)
+	if p.cfg.OnScale != nil {
+		p.cfg.OnScale(e)
+	}
+}
+
+// demonstrateAutoscaling feeds bursts of jobs into an autoscaling pool
+func demonstrateAutoscaling() {
+	fmt.Println("\n=== Autoscaling Worker Pool Demo ===")
+
+	cfg := PoolConfig{
+		MinWorkers:       1,
+		MaxWorkers:       8,
+		TargetQueueDelay: 200 * time.Millisecond,
+		ScaleDownAfter:   300 * time.Millisecond,
+		Interval:         50 * time.Millisecond,
+	}
+	fmt.Printf("Workers: min %d, max %d | target queue delay %v\n",
+		cfg.MinWorkers, cfg.MaxWorkers, cfg.TargetQueueDelay)
+
+	start := time.Now()
+	cfg.OnScale = func(e ScaleEvent) {
+		fmt.Printf("   [%5v] %v\n", e.Time.Sub(start).Round(time.Millisecond), e)
+	}
+
+	bursts := []int{40, 5, 60}
+	total := 0
+	for _, n := range bursts {
+		total += n
+	}
+	jobs := make(chan Job, total)
+
+	pool := NewAutoscalingPool(cfg, jobs, func(workerID int, job Job) {
+		time.Sleep(job.Work)
+	})
+	pool.Start()
+
+	// Bursty input: a flood, a trickle, then an even bigger flood
+	id := 0
+	for i, n := range bursts {
+		fmt.Printf("   [%5v] 📥 burst of %d jobs\n", time.Since(start).Round(time.Millisecond), n)
+		for j := 0; j < n; j++ {
+			id++
+			jobs <- Job{ID: id, Work: time.Duration(30+rand.Intn(30)) * time.Millisecond}
+		}
+		if i < len(bursts)-1 {
+			time.Sleep(1500 * time.Millisecond)
+		}
+	}
+	close(jobs)
+	pool.Wait()
+
+	fmt.Printf("\nProcessed %d jobs in %v using up to %d workers (%d scale events)\n",
+		total, time.Since(start).Round(time.Millisecond), pool.Peak(), len(pool.Events()))
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// BULKHEADS: Separate concurrency limits per source group
+// ============================================================================
+
+// ErrBulkheadFull is returned when a call waited too long for a slot
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// Bulkhead caps how many calls for one group run at once, like the
+// watertight compartments of a ship: when a group hangs, it fills its own
+// slots and no more. A call that finds every slot busy waits up to maxWait
+// and is then rejected. A nil *Bulkhead admits everything.
+type Bulkhead struct {
+	name     string
+	slots    chan struct{}
+	maxWait  time.Duration
+	admitted atomic.Int64
+	rejected atomic.Int64
+}
+
+// NewBulkhead creates a bulkhead with limit slots
+func NewBulkhead(name string, limit int, maxWait time.Duration) *Bulkhead {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Bulkhead{name: name, slots: make(chan struct{}, limit), maxWait: maxWait}
+}
+
+// Enter takes a slot, waiting up to maxWait for one. Call release when done.
+func (b *Bulkhead) Enter() (release func(), err error) {
+	if b == nil {
+		return func() {}, nil
+	}
+	select {
+	case b.slots <- struct{}{}:
+	default:
+		timer := time.NewTimer(b.maxWait)
+		defer timer.Stop()
+		select {
+		case b.slots <- struct{}{}:
+		case <-timer.C:
+			b.rejected.Add(1)
+			return nil, fmt.Errorf("%w: %s (%d calls running)", ErrBulkheadFull, b.name, cap(b.slots))
+		}
+	}
+	b.admitted.Add(1)
+	return func() { <-b.slots }, nil
+}
+
+// Bulkheads gives every source group a bulkhead of its own. A nil
+// *Bulkheads isolates nothing.
+type Bulkheads struct {
+	limit   int
+	maxWait time.Duration
+
+	mu      sync.Mutex
+	byGroup map[string]*Bulkhead
+}
+
+// NewBulkheads creates bulkheads of limit slots each, made on first use
+func NewBulkheads(limit int, maxWait time.Duration) *Bulkheads {
+	return &Bulkheads{limit: limit, maxWait: maxWait, byGroup: make(map[string]*Bulkhead)}
+}
+
+// sourceGroup is the group a source belongs to. Numbered sources share
+// their group's bulkhead (API-1 and API-2 are both "APIs", Database-1 and
+// Database-2 "Database"); anything else (Cache) has one of its own.
+func sourceGroup(source string) string {
+	name, n, ok := strings.Cut(source, "-")
+	if !ok || n == "" || strings.Trim(n, "0123456789") != "" {
+		return source
+	}
+	if name == "API" {
+		return "APIs"
+	}
+	return name
+}
+
+// For returns the bulkhead of a source's group
+func (bs *Bulkheads) For(source string) *Bulkhead {
+	if bs == nil {
+		return nil
+	}
+	group := sourceGroup(source)
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.byGroup[group]
+	if !ok {
+		b = NewBulkhead(group, bs.limit, bs.maxWait)
+		bs.byGroup[group] = b
+	}
+	return b
+}
+
+// Print reports what each group admitted and rejected
+func (bs *Bulkheads) Print() {
+	if bs == nil {
+		return
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	groups := make([]string, 0, len(bs.byGroup))
+	for group := range bs.byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	fmt.Printf("   🚧 Bulkheads (%d slots per group):\n", bs.limit)
+	for _, group := range groups {
+		b := bs.byGroup[group]
+		fmt.Printf("      %-10s %3d admitted, %3d rejected\n", group+":", b.admitted.Load(), b.rejected.Load())
+	}
+}
+
+// hangingFetch simulates a source group that has stopped answering
+const hangingFetch = 2 * time.Second
+
+// runGroupedFetches fetches every source through a shared pool of fetchers
+// (bulkheads nil) or through per-group bulkheads, and returns how long each
+// group took to finish and how many fetches were rejected
+func runGroupedFetches(sources []string, poolSize int, bulkheads *Bulkheads) (map[string]time.Duration, int) {
+	pool := make(chan struct{}, poolSize) // all groups share these fetchers
+	start := time.Now()
+
+	var (
+		mu       sync.Mutex
+		finished = make(map[string]time.Duration)
+		rejected int
+		wg       sync.WaitGroup
+	)
+	for _, source := range sources {
+		wg.Add(1)
+		go func(src string) {
+			defer wg.Done()
+			if bulkheads == nil {
+				pool <- struct{}{}
+				defer func() { <-pool }()
+			} else {
+				release, err := bulkheads.For(src).Enter()
+				if err != nil {
+					mu.Lock()
+					rejected++
+					mu.Unlock()
+					return
+				}
+				defer release()
+			}
+
+			if src == "Database" {
+				time.Sleep(hangingFetch)
+			} else {
+				fetchSource(src)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			group := sourceGroup(src)
+			if d := time.Since(start); d > finished[group] {
+				finished[group] = d
+			}
+		}(source)
+	}
+	wg.Wait()
+	return finished, rejected
+}
+
+// demonstrateBulkheads shows a hanging Database starving the APIs of a shared
+// pool, then the same load with a bulkhead per group
+func demonstrateBulkheads() {
+	fmt.Println("\n=== Bulkhead Isolation Demo ===")
+
+	// The Database requests arrive first and hang
+	var sources []string
+	for i := 0; i < 6; i++ {
+		sources = append(sources, "Database")
+	}
+	for i := 1; i <= 6; i++ {
+		sources = append(sources, fmt.Sprintf("API-%d", i), "Cache")
+	}
+	const poolSize = 6
+	fmt.Printf("%d requests: 6 to a Database that hangs for %v, 6 to APIs, 6 to the Cache\n", len(sources), hangingFetch)
+
+	report := func(finished map[string]time.Duration) {
+		for _, group := range []string{"APIs", "Cache", "Database"} {
+			if d, ok := finished[group]; ok {
+				fmt.Printf("      %-9s done after %v\n", group+":", d.Round(10*time.Millisecond))
+			} else {
+				fmt.Printf("      %-9s nothing completed\n", group+":")
+			}
+		}
+	}
+
+	fmt.Printf("\n🚢 One shared pool of %d fetchers:\n", poolSize)
+	finished, _ := runGroupedFetches(sources, poolSize, nil)
+	report(finished)
+
+	bulkheads := NewBulkheads(poolSize/3, 200*time.Millisecond)
+	fmt.Printf("\n🚧 A bulkhead of %d fetchers per group (wait up to 200ms for a slot):\n", poolSize/3)
+	finished, rejected := runGroupedFetches(sources, poolSize, bulkheads)
+	report(finished)
+	fmt.Printf("      %d request(s) rejected instead of piling up\n", rejected)
+	bulkheads.Print()
+
+	fmt.Println("\n💡 Without bulkheads the hanging group takes every fetcher and healthy")
+	fmt.Println("   groups wait behind it; with them it can only block its own slots.")
+}
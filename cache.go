@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// SHARED CACHE: RWMutex for reads, Once for setup, Cond to wait for a fetch
+// ============================================================================
+
+// SourceCache remembers what the simulated sources returned. Many goroutines
+// read it at once (RWMutex); the first use sets it up (Once); a goroutine
+// that wants a source someone else is already fetching waits for that fetch
+// instead of starting its own (Cond).
+type SourceCache struct {
+	fetch func(source string) string
+
+	once sync.Once
+	mu   sync.RWMutex
+	// fetched is signalled (under mu) whenever a fetch finishes
+	fetched  *sync.Cond
+	entries  map[string]string
+	fetching map[string]bool // sources with a fetch in flight
+
+	hits, misses, waits, fetches atomic.Int64
+}
+
+// NewSourceCache creates an empty cache that calls fetch on a miss
+func NewSourceCache(fetch func(source string) string) *SourceCache {
+	return &SourceCache{fetch: fetch}
+}
+
+// setup allocates the cache's maps on first use
+func (c *SourceCache) setup() {
+	c.once.Do(func() {
+		fmt.Println("   🔧 Cache initialised (sync.Once: exactly one goroutine does this)")
+		c.entries = make(map[string]string)
+		c.fetching = make(map[string]bool)
+		c.fetched = sync.NewCond(&c.mu)
+	})
+}
+
+// Get returns a source's data, fetching it if it is not cached
+func (c *SourceCache) Get(source string) string {
+	c.setup()
+
+	// Fast path: any number of readers hold the read lock together
+	c.mu.RLock()
+	data, ok := c.entries[source]
+	c.mu.RUnlock()
+	if ok {
+		c.hits.Add(1)
+		return data
+	}
+
+	// Slow path: take the write lock and either fetch or wait for the fetch
+	// another goroutine already started
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	waited := false
+	for c.fetching[source] {
+		waited = true
+		c.fetched.Wait() // unlocks mu while waiting
+	}
+	if data, ok := c.entries[source]; ok {
+		if waited {
+			c.waits.Add(1)
+		} else {
+			c.hits.Add(1) // filled in between RUnlock and Lock
+		}
+		return data
+	}
+
+	c.misses.Add(1)
+	return c.fetchLocked(source)
+}
+
+// fetchLocked is called, and returns, with mu held. It lets go of mu while
+// fetching, so readers of other sources are not held up, and wakes everyone
+// waiting for the source once its data is in.
+func (c *SourceCache) fetchLocked(source string) string {
+	c.fetching[source] = true
+	c.mu.Unlock()
+
+	c.fetches.Add(1)
+	data := c.fetch(source)
+
+	c.mu.Lock()
+	c.entries[source] = data
+	delete(c.fetching, source)
+	c.fetched.Broadcast()
+	return data
+}
+
+// Invalidate drops a source, so the next Get fetches it again
+func (c *SourceCache) Invalidate(source string) {
+	c.setup()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, source)
+}
+
+// PrintStats prints how the cache was used
+func (c *SourceCache) PrintStats() {
+	fmt.Printf("   Hits: %d | Misses: %d | Waited for another fetch: %d | Fetches: %d\n",
+		c.hits.Load(), c.misses.Load(), c.waits.Load(), c.fetches.Load())
+}
+
+// fetchSource calls the simulated fetchData for a single source
+func fetchSource(source string) string {
+	var wg sync.WaitGroup
+	dataChan := make(chan string, 1)
+	wg.Add(1)
+	fetchData(source, &wg, dataChan)
+	return <-dataChan
+}
+
+// readMostlyStore is a string map shared by many goroutines
+type readMostlyStore interface {
+	Load(key string) (string, bool)
+	Store(key, value string)
+}
+
+// mutexStore takes an exclusive lock even to read
+type mutexStore struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func (s *mutexStore) Load(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+func (s *mutexStore) Store(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+}
+
+// rwMutexStore lets readers share the lock
+type rwMutexStore struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+func (s *rwMutexStore) Load(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+func (s *rwMutexStore) Store(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+}
+
+// syncMapStore is a sync.Map, built for keys that are written once and read
+// many times
+type syncMapStore struct {
+	m sync.Map
+}
+
+func (s *syncMapStore) Load(key string) (string, bool) {
+	v, ok := s.m.Load(key)
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+
+func (s *syncMapStore) Store(key, value string) { s.m.Store(key, value) }
+
+// The benchmark's stores, in report order
+var readMostlyStores = []struct {
+	name  string
+	store func() readMostlyStore
+}{
+	{"Mutex", func() readMostlyStore { return &mutexStore{m: make(map[string]string)} }},
+	{"RWMutex", func() readMostlyStore { return &rwMutexStore{m: make(map[string]string)} }},
+	{"sync.Map", func() readMostlyStore { return &syncMapStore{} }},
+}
+
+// One operation in this many is a write in the read-heavy benchmark
+const cacheWriteEvery = 100
+
+// benchmarkStore measures a read-heavy mix of Loads and Stores, in
+// nanoseconds per operation
+func benchmarkStore(newStore func() readMostlyStore, goroutines int) float64 {
+	keys := make([]string, 64)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("source-%d", i)
+	}
+	return nsPerOp(func(n int) time.Duration {
+		s := newStore()
+		for _, k := range keys {
+			s.Store(k, k)
+		}
+		start := time.Now()
+		splitOps(n, goroutines, func(i int) {
+			key := keys[i%len(keys)]
+			if i%cacheWriteEvery == 0 {
+				s.Store(key, key)
+			} else {
+				s.Load(key)
+			}
+		})
+		return time.Since(start)
+	})
+}
+
+// demonstrateCache shows the cache at work, then benchmarks the locks
+func demonstrateCache() {
+	fmt.Println("\n=== Shared Cache Demo (RWMutex, Once, Cond, sync.Map) ===")
+
+	sources := []string{"API-1", "API-2", "API-3", "Database", "Cache"}
+	cache := NewSourceCache(fetchSource)
+
+	// 1. Twenty readers at once: one fetch per source, the rest wait or hit
+	fmt.Println("\n1️⃣  20 goroutines read 5 sources at the same time:")
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(source string) {
+			defer wg.Done()
+			cache.Get(source)
+		}(sources[i%len(sources)])
+	}
+	wg.Wait()
+	fmt.Printf("   Done in %v\n", time.Since(start).Round(time.Microsecond))
+	cache.PrintStats()
+
+	// 2. Cached now: reads never block each other
+	fmt.Println("\n2️⃣  The same 20 reads again (all served under the read lock):")
+	start = time.Now()
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(source string) {
+			defer wg.Done()
+			cache.Get(source)
+		}(sources[i%len(sources)])
+	}
+	wg.Wait()
+	fmt.Printf("   Done in %v\n", time.Since(start).Round(time.Microsecond))
+	cache.PrintStats()
+
+	// 3. Invalidate one source: its readers wait on the Cond for one refetch
+	fmt.Println("\n3️⃣  API-1 is invalidated, then 5 goroutines want it:")
+	cache.Invalidate("API-1")
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			data := cache.Get("API-1")
+			fmt.Printf("   Reader %d got %q\n", id, data)
+		}(i + 1)
+	}
+	wg.Wait()
+	cache.PrintStats()
+
+	// 4. Which lock suits a read-mostly map?
+	fmt.Printf("\n4️⃣  Read-heavy benchmark (1 write per %d operations), time per operation:\n", cacheWriteEvery)
+	fmt.Printf("\n   %-10s", "goroutines")
+	for _, s := range readMostlyStores {
+		fmt.Printf(" %10s", s.name)
+	}
+	fmt.Println()
+	for _, goroutines := range benchGoroutines() {
+		fmt.Printf("   %-10d", goroutines)
+		for _, s := range readMostlyStores {
+			fmt.Printf(" %8.1fns", benchmarkStore(s.store, goroutines))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("\n💡 RWMutex lets readers share the lock; sync.Map avoids locking on reads")
+	fmt.Println("   of keys that are rarely written. Both only pull ahead of a plain")
+	fmt.Println("   Mutex when several CPUs read at the same time.")
+}
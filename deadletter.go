@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// DEAD-LETTER QUEUE: Parking items that could not make it through the pipeline
+// ============================================================================
+
+// Pipeline stages an item can fail in
+const (
+	stageFetch   = "fetch"
+	stageProcess = "process"
+)
+
+// DeadLetter records an item that failed, why it failed and how often we tried
+type DeadLetter struct {
+	Stage        string       `json:"stage"`
+	Source       string       `json:"source"`
+	Item         *APIResponse `json:"item,omitempty"` // nil when the fetch itself failed
+	Error        string       `json:"error"`
+	Attempts     int          `json:"attempts"`
+	Replays      int          `json:"replays"`
+	FirstFailure time.Time    `json:"first_failure"`
+	LastFailure  time.Time    `json:"last_failure"`
+
+	replayOf int // the taken letter this one failed again as; 0 = a new failure
+}
+
+// validate rejects letters that cannot be replayed, such as a hand-edited
+// process letter without its item
+func (l DeadLetter) validate() error {
+	switch {
+	case l.Source == "":
+		return errors.New("letter has no source")
+	case l.Stage != stageFetch && l.Stage != stageProcess:
+		return fmt.Errorf("unknown stage %q", l.Stage)
+	case l.Stage == stageProcess && l.Item == nil:
+		return fmt.Errorf("%s letter for %s has no item", stageProcess, l.Source)
+	}
+	return nil
+}
+
+// fetchDeadLetter builds the dead letter for a source that could not be fetched
+func fetchDeadLetter(source string, err error, at time.Time) DeadLetter {
+	return DeadLetter{
+		Stage:        stageFetch,
+		Source:       source,
+		Error:        err.Error(),
+		Attempts:     1,
+		FirstFailure: at,
+		LastFailure:  at,
+	}
+}
+
+// DeadLetterQueue collects failed items (protected by mutex) and persists them
+// as JSON lines so they can be inspected and replayed by a later run
+type DeadLetterQueue struct {
+	mu      sync.Mutex
+	path    string
+	letters []DeadLetter
+	history map[int]DeadLetter // letters taken for replay, by number
+}
+
+// NewDeadLetterQueue creates an empty queue that Save writes to path
+func NewDeadLetterQueue(path string) *DeadLetterQueue {
+	return &DeadLetterQueue{path: path, history: make(map[int]DeadLetter)}
+}
+
+// OpenDeadLetterQueue loads any letters already stored at path
+func OpenDeadLetterQueue(path string) (*DeadLetterQueue, error) {
+	q := NewDeadLetterQueue(path)
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var l DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return nil, fmt.Errorf("reading %s:%d: %w", path, line, err)
+		}
+		if err := l.validate(); err != nil {
+			return nil, fmt.Errorf("reading %s:%d: %w", path, line, err)
+		}
+		q.letters = append(q.letters, l)
+	}
+	return q, scanner.Err()
+}
+
+// Add parks a failed item. If it is a replay of a taken letter (replayOf), that
+// letter's attempts and first failure time are carried over, even when it
+// failed in a different stage this time (a failed fetch that now fails
+// processing).
+func (q *DeadLetterQueue) Add(l DeadLetter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if prev, ok := q.history[l.replayOf]; ok {
+		l.Attempts += prev.Attempts
+		l.Replays = prev.Replays + 1
+		l.FirstFailure = prev.FirstFailure
+		delete(q.history, l.replayOf)
+	}
+	l.replayOf = 0
+	q.letters = append(q.letters, l)
+}
+
+// Len returns the number of parked items
+func (q *DeadLetterQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.letters)
+}
+
+// Letters returns a copy of the parked items
+func (q *DeadLetterQueue) Letters() []DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]DeadLetter(nil), q.letters...)
+}
+
+// TakeForReplay empties the queue and remembers what was taken, so items that
+// fail again keep their history when they are added back. The nth letter
+// taken is number n: a replay of it must fail with replayOf (or, for an item,
+// Letter) set to n, so that a source with several letters gets each one's
+// history right.
+func (q *DeadLetterQueue) TakeForReplay() []DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	taken := q.letters
+	q.letters = nil
+	q.history = make(map[int]DeadLetter, len(taken))
+	for i, l := range taken {
+		q.history[i+1] = l
+	}
+	return taken
+}
+
+// Save writes the parked items to the queue's file, removing it when empty
+func (q *DeadLetterQueue) Save() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.letters) == 0 {
+		if err := os.Remove(q.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	f, err := os.Create(q.path)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, l := range q.letters {
+		if err := enc.Encode(l); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// Print lists the parked items
+func (q *DeadLetterQueue) Print() {
+	letters := q.Letters()
+	if len(letters) == 0 {
+		fmt.Printf("📭 Dead-letter queue %s is empty\n", q.path)
+		return
+	}
+
+	fmt.Printf("☠️  Dead-letter queue %s (%d item(s)):\n", q.path, len(letters))
+	for i, l := range letters {
+		fmt.Printf("   %d. [%s] %s after %d attempt(s), %d replay(s)\n",
+			i+1, l.Stage, l.Source, l.Attempts, l.Replays)
+		fmt.Printf("      error: %s\n", l.Error)
+		fmt.Printf("      first failed %s, last failed %s\n",
+			l.FirstFailure.Format(time.RFC3339), l.LastFailure.Format(time.RFC3339))
+	}
+}
+
+// replayDeadLetters pushes every parked item back through the pipeline, with
+// -processor and -max-workers workers: failed fetches are fetched again,
+// failed items are processed again. Anything that still fails goes back into
+// the queue.
+func replayDeadLetters(dlq *DeadLetterQueue) error {
+	processor, err := lookupProcessor(*processorName)
+	if err != nil {
+		return err
+	}
+	letters := dlq.TakeForReplay()
+	if len(letters) == 0 {
+		fmt.Println("📭 Nothing to replay")
+		return nil
+	}
+	fmt.Printf("🔁 Replaying %d dead-lettered item(s)...\n", len(letters))
+
+	stats := NewStats()
+	defer stats.Close()
+	jobs := make(chan *APIResponse, len(letters))
+	processedData := make(chan ProcessedData, len(letters))
+	done := make(chan int)
+
+	var fetchWg sync.WaitGroup
+	for i, l := range letters {
+		n := i + 1 // the letter's number in TakeForReplay
+		switch l.Stage {
+		case stageFetch:
+			fetchWg.Add(1)
+			go func(src string) {
+				defer fetchWg.Done()
+				response, err := fetchWithTimeout(src, fetchTimeout, stats)
+				if err != nil {
+					fmt.Printf("   ⚠️  Error: %v\n", err)
+					stats.IncrementDeadLettered()
+					again := fetchDeadLetter(src, err, time.Now())
+					again.replayOf = n
+					dlq.Add(again)
+					return
+				}
+				response.Letter = n
+				jobs <- response
+			}(l.Source)
+		case stageProcess:
+			l.Item.Letter = n
+			jobs <- l.Item
+		}
+	}
+
+	go func() {
+		fetchWg.Wait()
+		close(jobs)
+	}()
+
+	var processWg sync.WaitGroup
+	for w := 1; w <= *maxWorkers; w++ {
+		processWg.Add(1)
+		go processingWorker(w, jobs, processedData, processor, dlq, stats, &processWg)
+	}
+
+	go func() {
+		processWg.Wait()
+		close(processedData)
+	}()
+
+	go outputPipeline(processedData, done)
+	<-done
+
+	stats.Print()
+	return nil
+}
+
+// runDeadLetterCommand implements `dlq list|replay|purge`
+func runDeadLetterCommand(path string, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: dlq list|replay|purge")
+	}
+
+	dlq, err := OpenDeadLetterQueue(path)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		dlq.Print()
+		return nil
+	case "replay":
+		if err := replayDeadLetters(dlq); err != nil {
+			return err
+		}
+		if err := dlq.Save(); err != nil {
+			return err
+		}
+		dlq.Print()
+		return nil
+	case "purge":
+		n := len(dlq.TakeForReplay())
+		if err := dlq.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Purged %d item(s) from %s\n", n, path)
+		return nil
+	default:
+		return fmt.Errorf("unknown dlq command %q (want list, replay or purge)", args[0])
+	}
+}
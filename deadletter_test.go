@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReplayHistoryPerLetter(t *testing.T) {
+	early := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+	q := NewDeadLetterQueue("")
+	q.Add(DeadLetter{Stage: stageProcess, Source: "API-1", Item: &APIResponse{Source: "API-1"}, Attempts: 3, FirstFailure: early})
+	q.Add(DeadLetter{Stage: stageProcess, Source: "API-1", Item: &APIResponse{Source: "API-1"}, Attempts: 1, FirstFailure: late})
+	q.Add(fetchDeadLetter("API-1", errors.New("timeout"), late))
+
+	if taken := q.TakeForReplay(); len(taken) != 3 {
+		t.Fatalf("took %d letters, want 3", len(taken))
+	}
+
+	// Only the second letter fails again, the first and third get through
+	q.Add(DeadLetter{Stage: stageProcess, Source: "API-1", Attempts: 1, FirstFailure: time.Now(), replayOf: 2})
+	letters := q.Letters()
+	if len(letters) != 1 {
+		t.Fatalf("%d letters back in the queue, want 1", len(letters))
+	}
+	if got := letters[0]; got.Attempts != 2 || got.Replays != 1 || !got.FirstFailure.Equal(late) {
+		t.Errorf("letter back in the queue has %d attempts, %d replays, first failure %v; want letter 2's history: 2, 1, %v",
+			got.Attempts, got.Replays, got.FirstFailure, late)
+	}
+
+	// A new failure of the same source starts a history of its own
+	q.Add(fetchDeadLetter("API-1", errors.New("timeout"), time.Now()))
+	if got := q.Letters()[1]; got.Attempts != 1 || got.Replays != 0 {
+		t.Errorf("new letter has %d attempts, %d replays, want 1 and 0", got.Attempts, got.Replays)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// FAIR SCHEDULING: One queue per source, deficit round robin into the workers
+// ============================================================================
+
+// sourceQueue holds the waiting items of one source
+type sourceQueue[T any] struct {
+	name    string
+	weight  int
+	deficit int
+	items   []T
+}
+
+// DRRScheduler picks items from per-source queues with deficit round robin.
+// Each turn a source earns quantum × weight credit and spends it on items
+// (cost 1 each unless costOf says otherwise), so a source with weight 2 gets
+// twice the share of one with weight 1 - no matter how much it has queued.
+// It is not safe for concurrent use; dispatchFairly owns it.
+type DRRScheduler[T any] struct {
+	quantum  int
+	weights  map[string]int
+	sourceOf func(T) string
+	costOf   func(T) int
+
+	queues      map[string]*sourceQueue[T]
+	active      []*sourceQueue[T] // sources with items, in round-robin order
+	pos         int
+	turnStarted bool
+	size        int
+}
+
+// NewDRRScheduler creates a scheduler. Sources missing from weights get weight 1;
+// a nil costOf makes every item cost 1.
+func NewDRRScheduler[T any](quantum int, weights map[string]int, sourceOf func(T) string, costOf func(T) int) *DRRScheduler[T] {
+	if quantum < 1 {
+		quantum = 1
+	}
+	if costOf == nil {
+		costOf = func(T) int { return 1 }
+	}
+	return &DRRScheduler[T]{
+		quantum:  quantum,
+		weights:  weights,
+		sourceOf: sourceOf,
+		costOf:   costOf,
+		queues:   make(map[string]*sourceQueue[T]),
+	}
+}
+
+// Push queues an item behind the others from the same source
+func (s *DRRScheduler[T]) Push(item T) {
+	name := s.sourceOf(item)
+	q, ok := s.queues[name]
+	if !ok {
+		weight := s.weights[name]
+		if weight < 1 {
+			weight = 1
+		}
+		q = &sourceQueue[T]{name: name, weight: weight}
+		s.queues[name] = q
+	}
+	if len(q.items) == 0 {
+		s.active = append(s.active, q)
+	}
+	q.items = append(q.items, item)
+	s.size++
+}
+
+// Len returns the number of queued items across all sources
+func (s *DRRScheduler[T]) Len() int { return s.size }
+
+// Next removes and returns the next item in fair order
+func (s *DRRScheduler[T]) Next() (T, bool) {
+	for len(s.active) > 0 {
+		q := s.active[s.pos]
+		if !s.turnStarted {
+			q.deficit += s.quantum * q.weight
+			s.turnStarted = true
+		}
+
+		if cost := s.costOf(q.items[0]); q.deficit >= cost {
+			item := q.items[0]
+			q.items = q.items[1:]
+			q.deficit -= cost
+			s.size--
+
+			// An emptied source leaves the rotation and loses its credit
+			if len(q.items) == 0 {
+				q.deficit = 0
+				s.active = append(s.active[:s.pos], s.active[s.pos+1:]...)
+				if s.pos >= len(s.active) {
+					s.pos = 0
+				}
+				s.turnStarted = false
+			}
+			return item, true
+		}
+
+		// Out of credit: the turn passes to the next source
+		s.pos = (s.pos + 1) % len(s.active)
+		s.turnStarted = false
+	}
+
+	var zero T
+	return zero, false
+}
+
+// dispatchFairly sits between a shared input channel and the workers, like
+// dispatchByPriority. out should be unbuffered so items wait in their source's
+// queue until a worker is free. out is closed once in is closed and drained.
+func dispatchFairly[T any](in <-chan T, out chan<- T, sched *DRRScheduler[T]) {
+	for {
+		// Nothing queued: block until something arrives
+		if sched.Len() == 0 {
+			item, ok := <-in
+			if !ok {
+				close(out)
+				return
+			}
+			sched.Push(item)
+			continue
+		}
+
+		next, _ := sched.Next()
+		for sent := false; !sent; {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					// Input finished: drain the rest in fair order
+					out <- next
+					for sched.Len() > 0 {
+						item, _ := sched.Next()
+						out <- item
+					}
+					close(out)
+					return
+				}
+				sched.Push(item)
+			case out <- next:
+				sent = true
+			}
+		}
+	}
+}
+
+// runFairPool sends a chatty source and a few quiet ones through numWorkers
+// workers and returns when each source's last item finished
+func runFairPool(items []*APIResponse, numWorkers int, sched *DRRScheduler[*APIResponse], stats *Stats) map[string]time.Duration {
+	arrivals := make(chan *APIResponse, len(items))
+	work := (<-chan *APIResponse)(arrivals)
+	if sched != nil {
+		dispatched := make(chan *APIResponse)
+		go dispatchFairly(arrivals, dispatched, sched)
+		work = dispatched
+	}
+
+	start := time.Now()
+	var mu sync.Mutex
+	finished := make(map[string]time.Duration)
+
+	var wg sync.WaitGroup
+	for w := 1; w <= numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				time.Sleep(20 * time.Millisecond)
+				stats.IncrementProcessed(item.Source)
+
+				mu.Lock()
+				finished[item.Source] = time.Since(start)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, item := range items {
+		arrivals <- item
+	}
+	close(arrivals)
+
+	wg.Wait()
+	return finished
+}
+
+// demonstrateFairScheduling shows a noisy source starving quiet ones under
+// FIFO, and deficit round robin giving everyone their share
+func demonstrateFairScheduling() {
+	fmt.Println("\n=== Fair Scheduling Demo ===")
+
+	numWorkers := 2
+	var items []*APIResponse
+	add := func(source string, n int) {
+		for i := 1; i <= n; i++ {
+			items = append(items, &APIResponse{Source: source, Data: fmt.Sprintf("%s-%d", source, i)})
+		}
+	}
+	// The chatty source dumps its whole backlog first
+	add("Chatty-API", 40)
+	add("API-2", 5)
+	add("API-3", 5)
+	add("Database", 5)
+
+	weights := map[string]int{"Database": 2}
+	fmt.Printf("%d items, %d workers, weights: Database=2, others=1\n", len(items), numWorkers)
+
+	report := func(label string, finished map[string]time.Duration, stats *Stats) {
+		fmt.Printf("\n%s\n", label)
+		names := make([]string, 0, len(finished))
+		for name := range finished {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("   %-10s last item done after %v\n", name, finished[name].Round(time.Millisecond))
+		}
+		stats.Print()
+	}
+
+	fifoStats := NewStats()
+	defer fifoStats.Close()
+	report("📥 FIFO (one shared channel):", runFairPool(items, numWorkers, nil, fifoStats), fifoStats)
+
+	drrStats := NewStats()
+	defer drrStats.Close()
+	sched := NewDRRScheduler(1, weights, func(r *APIResponse) string { return r.Source }, nil)
+	report("⚖️  Deficit round robin (one queue per source):", runFairPool(items, numWorkers, sched, drrStats), drrStats)
+
+	fmt.Println("\nWith DRR the quiet sources finish early instead of waiting behind Chatty-API.")
+}
@@ -0,0 +1,305 @@
+// Package faults makes simulated calls misbehave on purpose, so that
+// timeouts, retries and error paths can be exercised on demand instead of
+// waiting for bad luck.
+//
+// An Injector holds rules per target (a source, a worker) and rolls a Fault
+// for every call. The rolls come from a seeded generator per target, so the
+// nth call to a target gets the same fault every time the same seed is used,
+// however the goroutines happen to be scheduled:
+//
+//	in, err := faults.Parse("API-3=error:0.3,hang:0.1;Worker-*=slow:1:200ms", 42)
+//	...
+//	fault := in.Roll("API-3")
+//	if err := fault.Apply(ctx); err != nil {
+//		return err
+//	}
+//	data = fault.Cut(data)
+//
+// A nil *Injector is valid and never injects anything.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is a way for a call to misbehave
+type Kind int
+
+const (
+	None    Kind = iota
+	Error        // the call fails
+	Spike        // the call takes Duration longer than usual
+	Hang         // the call never answers (until its context ends, or for Duration)
+	Panic        // the call panics
+	Partial      // the call answers with only half of its data
+	Slow         // a slow consumer: Duration of extra work per call
+)
+
+var kindNames = []string{"none", "error", "spike", "hang", "panic", "partial", "slow"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+// parseKind is the inverse of String
+func parseKind(name string) (Kind, error) {
+	for k, n := range kindNames {
+		if n == name && Kind(k) != None {
+			return Kind(k), nil
+		}
+	}
+	return None, fmt.Errorf("unknown fault %q (want one of %s)", name, strings.Join(kindNames[1:], ", "))
+}
+
+// Default durations for faults given without one
+var defaultDuration = map[Kind]time.Duration{
+	Spike: 500 * time.Millisecond,
+	Slow:  200 * time.Millisecond,
+}
+
+// ErrInjected is wrapped by every error an Error fault returns
+var ErrInjected = errors.New("injected fault")
+
+// Rule injects Kind into a share Rate (0-1) of calls
+type Rule struct {
+	Kind     Kind
+	Rate     float64
+	Duration time.Duration // for Spike, Slow and Hang (0 = until cancelled)
+}
+
+// Fault is what a single call should do
+type Fault struct {
+	Kind     Kind
+	Target   string
+	Duration time.Duration
+}
+
+// Apply carries out the fault: it waits out a Spike, Slow or Hang (returning
+// ctx's error if ctx ends first), panics for Panic and returns an error
+// wrapping ErrInjected for Error. Partial and None return nil at once.
+func (f Fault) Apply(ctx context.Context) error {
+	switch f.Kind {
+	case Error:
+		return fmt.Errorf("%w: %s failed", ErrInjected, f.Target)
+	case Panic:
+		panic(fmt.Sprintf("faults: injected panic in %s", f.Target))
+	case Spike, Slow, Hang:
+		var elapsed <-chan time.Time
+		if f.Duration > 0 {
+			timer := time.NewTimer(f.Duration)
+			defer timer.Stop()
+			elapsed = timer.C
+		}
+		select {
+		case <-elapsed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Cut returns the first half of data for a Partial fault, data otherwise
+func (f Fault) Cut(data string) string {
+	if f.Kind != Partial {
+		return data
+	}
+	return data[:len(data)/2]
+}
+
+// targetRules are the rules for targets matching a pattern
+type targetRules struct {
+	pattern string
+	rules   []Rule
+}
+
+// Injector decides which calls misbehave. It is safe for concurrent use.
+type Injector struct {
+	seed int64
+
+	mu       sync.Mutex
+	targets  []targetRules
+	rngs     map[string]*rand.Rand
+	injected map[string]map[Kind]int
+}
+
+// New creates an injector with no rules whose rolls derive from seed
+func New(seed int64) *Injector {
+	return &Injector{
+		seed:     seed,
+		rngs:     make(map[string]*rand.Rand),
+		injected: make(map[string]map[Kind]int),
+	}
+}
+
+// Parse creates an injector from a spec such as
+//
+//	API-3=error:0.3,spike:0.2:800ms;Worker-*=panic:0.05
+//
+// Each ';'-separated part gives a target pattern (path.Match syntax) and its
+// rules as kind:rate[:duration]. A call gets the rules of the first pattern
+// its target matches.
+func Parse(spec string, seed int64) (*Injector, error) {
+	in := New(seed)
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pattern, list, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("fault rule %q: want target=kind:rate[,...]", part)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("fault target %q: %v", pattern, err)
+		}
+		var rules []Rule
+		for _, field := range strings.Split(list, ",") {
+			rule, err := parseRule(strings.TrimSpace(field))
+			if err != nil {
+				return nil, fmt.Errorf("fault rule for %s: %v", pattern, err)
+			}
+			rules = append(rules, rule)
+		}
+		in.Add(pattern, rules...)
+	}
+	return in, nil
+}
+
+// parseRule parses kind:rate[:duration]
+func parseRule(field string) (Rule, error) {
+	parts := strings.Split(field, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Rule{}, fmt.Errorf("%q: want kind:rate[:duration]", field)
+	}
+	kind, err := parseKind(parts[0])
+	if err != nil {
+		return Rule{}, err
+	}
+	rate, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return Rule{}, fmt.Errorf("%q: rate must be between 0 and 1", field)
+	}
+	rule := Rule{Kind: kind, Rate: rate, Duration: defaultDuration[kind]}
+	if len(parts) == 3 {
+		if rule.Duration, err = time.ParseDuration(parts[2]); err != nil {
+			return Rule{}, fmt.Errorf("%q: %v", field, err)
+		}
+	}
+	return rule, nil
+}
+
+// Add gives targets matching pattern (path.Match syntax, e.g. "API-*") the
+// rules, checked in order. Earlier patterns win over later ones.
+func (in *Injector) Add(pattern string, rules ...Rule) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.targets = append(in.targets, targetRules{pattern, rules})
+}
+
+// Seed returns the seed the injector's rolls derive from
+func (in *Injector) Seed() int64 {
+	if in == nil {
+		return 0
+	}
+	return in.seed
+}
+
+// Roll decides what the next call to target does. Every call draws one
+// number per rule, so the sequence of faults a target gets depends only on
+// the seed and how often it is called.
+func (in *Injector) Roll(target string) Fault {
+	fault := Fault{Kind: None, Target: target}
+	if in == nil {
+		return fault
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	rules := in.rulesFor(target)
+	if len(rules) == 0 {
+		return fault
+	}
+	rng, ok := in.rngs[target]
+	if !ok {
+		h := fnv.New64a()
+		h.Write([]byte(target))
+		rng = rand.New(rand.NewSource(in.seed ^ int64(h.Sum64())))
+		in.rngs[target] = rng
+	}
+	for _, rule := range rules {
+		if rng.Float64() < rule.Rate && fault.Kind == None {
+			fault.Kind, fault.Duration = rule.Kind, rule.Duration
+		}
+	}
+	if fault.Kind != None {
+		if in.injected[target] == nil {
+			in.injected[target] = make(map[Kind]int)
+		}
+		in.injected[target][fault.Kind]++
+	}
+	return fault
+}
+
+// rulesFor returns the rules of the first pattern target matches
+func (in *Injector) rulesFor(target string) []Rule {
+	for _, t := range in.targets {
+		if ok, _ := path.Match(t.pattern, target); ok {
+			return t.rules
+		}
+	}
+	return nil
+}
+
+// Injected returns how many faults of each kind target was given
+func (in *Injector) Injected(target string) map[Kind]int {
+	counts := make(map[Kind]int)
+	if in == nil {
+		return counts
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for kind, n := range in.injected[target] {
+		counts[kind] = n
+	}
+	return counts
+}
+
+// Report describes every fault injected so far, one line per target
+func (in *Injector) Report() string {
+	if in == nil {
+		return ""
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	targets := make([]string, 0, len(in.injected))
+	for target := range in.injected {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	var b strings.Builder
+	for _, target := range targets {
+		fmt.Fprintf(&b, "%-10s", target+":")
+		for kind := Error; kind <= Slow; kind++ {
+			if n := in.injected[target][kind]; n > 0 {
+				fmt.Fprintf(&b, " %s×%d", kind, n)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
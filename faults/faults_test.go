@@ -0,0 +1,188 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string // part of the error
+	}{
+		{"API-1", "want target=kind:rate"},
+		{"API-1=boom:0.5", `unknown fault "boom"`},
+		{"API-1=none:0.5", `unknown fault "none"`},
+		{"API-1=error", "want kind:rate[:duration]"},
+		{"API-1=error:0.1:1s:2", "want kind:rate[:duration]"},
+		{"API-1=error:x", "rate must be between 0 and 1"},
+		{"API-1=error:1.5", "rate must be between 0 and 1"},
+		{"API-1=error:-0.1", "rate must be between 0 and 1"},
+		{"API-1=spike:0.5:soon", `invalid duration "soon"`},
+		{"[=error:0.1", "fault target"},
+		{"API-1=error:0.1;API-2=hang", "fault rule for API-2"},
+	}
+	for _, tt := range tests {
+		in, err := Parse(tt.spec, 1)
+		if err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", tt.spec, in)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %q, want it to mention %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	in, err := Parse(" API-3=error:0.3, spike:0.2:800ms ; Worker-*=slow:1 ;", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := in.Seed(); got != 7 {
+		t.Errorf("Seed() = %d, want 7", got)
+	}
+	want := []Rule{{Error, 0.3, 0}, {Spike, 0.2, 800 * time.Millisecond}}
+	if got := in.rulesFor("API-3"); !equalRules(got, want) {
+		t.Errorf("rules for API-3 = %v, want %v", got, want)
+	}
+	want = []Rule{{Slow, 1, defaultDuration[Slow]}}
+	if got := in.rulesFor("Worker-2"); !equalRules(got, want) {
+		t.Errorf("rules for Worker-2 = %v, want %v (the default duration)", got, want)
+	}
+	if got := in.rulesFor("API-1"); got != nil {
+		t.Errorf("rules for API-1 = %v, want none", got)
+	}
+	if f := in.Roll("Worker-1"); f.Kind != Slow || f.Target != "Worker-1" {
+		t.Errorf("Roll(Worker-1) = %+v, want a Slow fault at rate 1", f)
+	}
+}
+
+func equalRules(a, b []Rule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rolls returns the kinds of n consecutive rolls for target
+func rolls(in *Injector, target string, n int) []Kind {
+	kinds := make([]Kind, n)
+	for i := range kinds {
+		kinds[i] = in.Roll(target).Kind
+	}
+	return kinds
+}
+
+func TestSameSeedSameFaults(t *testing.T) {
+	const spec = "API-*=error:0.3,hang:0.2,partial:0.2"
+	a, _ := Parse(spec, 42)
+	b, _ := Parse(spec, 42)
+
+	// b's rolls for API-1 are interleaved with another target's: each
+	// target has a generator of its own, so that must not matter
+	want := rolls(a, "API-1", 200)
+	got := make([]Kind, 0, 200)
+	for i := 0; i < 200; i++ {
+		b.Roll("API-2")
+		got = append(got, b.Roll("API-1").Kind)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("roll %d for API-1 = %v with the same seed, want %v", i, got[i], want[i])
+		}
+	}
+
+	injected := 0
+	for _, n := range a.Injected("API-1") {
+		injected += n
+	}
+	if injected == 0 || injected == 200 {
+		t.Errorf("%d of 200 calls got a fault, want some but not all", injected)
+	}
+
+	c, _ := Parse(spec, 43)
+	same := true
+	for i, kind := range rolls(c, "API-1", 200) {
+		same = same && kind == want[i]
+	}
+	if same {
+		t.Error("another seed gave the very same 200 faults")
+	}
+}
+
+func TestNilInjector(t *testing.T) {
+	var in *Injector
+	if f := in.Roll("API-1"); f.Kind != None {
+		t.Errorf("nil injector rolled %v, want none", f.Kind)
+	}
+	if r := in.Report(); r != "" {
+		t.Errorf("nil injector reported %q", r)
+	}
+}
+
+func TestApply(t *testing.T) {
+	const short = 20 * time.Millisecond
+	tests := []struct {
+		fault   Fault
+		timeout time.Duration // for the context; 0 = none
+		wantErr error
+		minWait time.Duration
+	}{
+		{Fault{Kind: None}, 0, nil, 0},
+		{Fault{Kind: Partial}, 0, nil, 0},
+		{Fault{Kind: Error, Target: "API-1"}, 0, ErrInjected, 0},
+		{Fault{Kind: Spike, Duration: short}, 0, nil, short},
+		{Fault{Kind: Slow, Duration: short}, 0, nil, short},
+		{Fault{Kind: Hang, Duration: short}, 0, nil, short},
+		{Fault{Kind: Hang}, short, context.DeadlineExceeded, short},
+		{Fault{Kind: Spike, Duration: time.Hour}, short, context.DeadlineExceeded, short},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+			defer cancel()
+		}
+		start := time.Now()
+		err := tt.fault.Apply(ctx)
+		if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+			t.Errorf("%v.Apply() = %v, want %v", tt.fault.Kind, err, tt.wantErr)
+		}
+		if elapsed := time.Since(start); elapsed < tt.minWait {
+			t.Errorf("%v.Apply() returned after %v, want at least %v", tt.fault.Kind, elapsed, tt.minWait)
+		}
+	}
+}
+
+func TestApplyPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "Worker-1") {
+			t.Errorf("recovered %v, want the injected panic for Worker-1", r)
+		}
+	}()
+	Fault{Kind: Panic, Target: "Worker-1"}.Apply(context.Background())
+	t.Error("Apply returned for a Panic fault")
+}
+
+func TestCut(t *testing.T) {
+	const data = "data-from-API-1"
+	for kind := None; kind <= Slow; kind++ {
+		want := data
+		if kind == Partial {
+			want = data[:len(data)/2]
+		}
+		if got := (Fault{Kind: kind}).Cut(data); got != want {
+			t.Errorf("%v.Cut(%q) = %q, want %q", kind, data, got, want)
+		}
+	}
+}
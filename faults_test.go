@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-concurrency-demo/faults"
+)
+
+// useFaults injects spec for the rest of the test
+func useFaults(t *testing.T, spec string) {
+	t.Helper()
+	in, err := faults.Parse(spec, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := injector
+	injector = in
+	t.Cleanup(func() { injector = saved })
+}
+
+func TestWorkerHangIsBounded(t *testing.T) {
+	useFaults(t, "Worker-*=hang:1")
+	processor := withFaults(ProcessFunc(simulateProcessing), "Worker-1")
+
+	tests := []struct {
+		name   string
+		budget time.Duration // 0 = none
+		within time.Duration
+	}{
+		{"no budget", 0, fetchTimeout},
+		{"short budget", 100 * time.Millisecond, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		job := &APIResponse{Source: "API-1", Data: "data-from-API-1"}
+		if tt.budget > 0 {
+			job.Deadline = time.Now().Add(tt.budget)
+		}
+
+		done := make(chan error, 1)
+		start := time.Now()
+		go func() {
+			_, err := processor.Process(job)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("%s: hanging worker returned %v, want %v", tt.name, err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > tt.within+500*time.Millisecond {
+				t.Errorf("%s: hanging worker gave up after %v, want about %v", tt.name, elapsed, tt.within)
+			}
+		case <-time.After(tt.within + 5*time.Second):
+			t.Fatalf("%s: hanging worker never gave up", tt.name)
+		}
+	}
+}
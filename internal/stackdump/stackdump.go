@@ -0,0 +1,201 @@
+// Package stackdump captures and parses the stacks of every running goroutine,
+// in the text format printed by runtime.Stack and by an unrecovered panic.
+package stackdump
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Frame is one function call on a goroutine's stack
+type Frame struct {
+	Func string // package-qualified, e.g. "main.worker" or "sync.(*WaitGroup).Wait"
+	File string
+	Line int
+}
+
+func (f Frame) String() string {
+	return f.Func + " (" + f.File + ":" + strconv.Itoa(f.Line) + ")"
+}
+
+// FuncName returns the function's name without its package path, e.g.
+// "pipelineStage1" or "(*WaitGroup).Wait" or "demonstrateIntegrated.func2"
+func (f Frame) FuncName() string {
+	name := f.Func
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if _, after, ok := strings.Cut(name, "."); ok {
+		return after
+	}
+	return name
+}
+
+// Goroutine is one goroutine from a stack dump
+type Goroutine struct {
+	ID        int
+	State     string // why it is parked, e.g. "chan receive", "select", "running"
+	Waiting   string // how long it has been parked, e.g. "2 minutes" (often empty)
+	Frames    []Frame
+	CreatedBy Frame  // the go statement that started it (empty for main)
+	Stack     string // the goroutine's section of the dump, verbatim
+}
+
+// Top returns the innermost frame, where the goroutine currently is
+func (g Goroutine) Top() Frame {
+	if len(g.Frames) == 0 {
+		return Frame{}
+	}
+	return g.Frames[0]
+}
+
+// HasFunc reports whether any frame's function starts with prefix
+func (g Goroutine) HasFunc(prefix string) bool {
+	for _, f := range g.Frames {
+		if strings.HasPrefix(f.Func, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRuntime reports whether every frame is in the runtime: such goroutines
+// are runtime helpers, not the program's own
+func (g Goroutine) IsRuntime() bool {
+	for _, f := range g.Frames {
+		if !strings.HasPrefix(f.Func, "runtime.") {
+			return false
+		}
+	}
+	return true
+}
+
+// Capture returns the raw stacks of every goroutine
+func Capture() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// All returns every running goroutine
+func All() []Goroutine {
+	return Parse(Capture())
+}
+
+// CurrentID returns the ID of the calling goroutine
+func CurrentID() int {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	if gs := Parse(buf); len(gs) > 0 {
+		return gs[0].ID
+	}
+	return 0
+}
+
+var headerPattern = regexp.MustCompile(`^goroutine (\d+)(?: [^\[]*)?\[(.*)\]:$`)
+
+// Parse parses a dump of one or more goroutines
+func Parse(dump []byte) []Goroutine {
+	var (
+		gs    []Goroutine
+		cur   *Goroutine
+		start int // offset of the current goroutine's header
+		pos   int // offset of the next line
+	)
+	finish := func(end int) {
+		if cur != nil {
+			cur.Stack = strings.TrimRight(string(dump[start:end]), "\n")
+			gs = append(gs, *cur)
+			cur = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(dump))
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	var pendingFunc string
+	var pendingCreatedBy bool
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineStart := pos
+		pos += len(line) + 1
+
+		if m := headerPattern.FindStringSubmatch(line); m != nil {
+			finish(lineStart)
+			id, _ := strconv.Atoi(m[1])
+			cur = &Goroutine{ID: id}
+			start = lineStart
+			parts := strings.Split(m[2], ", ")
+			cur.State = parts[0]
+			for _, p := range parts[1:] {
+				if strings.HasSuffix(p, "minutes") || strings.HasSuffix(p, "minute") {
+					cur.Waiting = p
+				}
+			}
+			pendingFunc = ""
+			continue
+		}
+		if cur == nil || line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "\t") {
+			// "\t/path/file.go:123 +0x1d" completes the pending function
+			if pendingFunc == "" {
+				continue
+			}
+			file, lineNo := parseLocation(strings.TrimSpace(line))
+			frame := Frame{Func: pendingFunc, File: file, Line: lineNo}
+			if pendingCreatedBy {
+				cur.CreatedBy = frame
+			} else {
+				cur.Frames = append(cur.Frames, frame)
+			}
+			pendingFunc = ""
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, "created by "); ok {
+			if i := strings.Index(rest, " in goroutine "); i >= 0 {
+				rest = rest[:i]
+			}
+			pendingFunc, pendingCreatedBy = rest, true
+			continue
+		}
+		if strings.HasPrefix(line, "...") {
+			continue // "...additional frames elided..."
+		}
+		pendingFunc, pendingCreatedBy = funcName(line), false
+	}
+	finish(len(dump))
+	return gs
+}
+
+// funcName strips the argument list from "pkg.fn(0xc000010000, 0x3)"
+func funcName(line string) string {
+	if i := strings.LastIndex(line, "("); i > 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// parseLocation splits "/path/file.go:123 +0x1d"
+func parseLocation(s string) (string, int) {
+	if i := strings.LastIndex(s, " +0x"); i >= 0 {
+		s = s[:i]
+	}
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return s, 0
+	}
+	n, _ := strconv.Atoi(s[i+1:])
+	return s[:i], n
+}
@@ -0,0 +1,169 @@
+// Package leakcheck finds goroutines that are still running when they should
+// have exited.
+//
+// In a demo, take a Snapshot before running it and ask for the goroutines
+// that were started since and never finished:
+//
+//	snapshot := leakcheck.Take()
+//	runDemo()
+//	if leaks := snapshot.Leaked(); len(leaks) > 0 {
+//		fmt.Print(leakcheck.Report(leaks))
+//	}
+//
+// In a test, call Verify first thing to fail the test instead:
+//
+//	func TestPipeline(t *testing.T) {
+//		leakcheck.Verify(t)
+//		...
+//	}
+//
+// (or defer VerifyNone, or Snapshot.Verify, where a test needs more control).
+//
+// Goroutines get a grace period to finish on their own, so one that is
+// merely on its way out (a fetch that lost a select to a timeout, say) is
+// only reported if it is still there after Grace.
+package leakcheck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang-concurrency-demo/internal/stackdump"
+)
+
+// Grace is how long the checks wait for goroutines to exit before calling
+// them leaked
+var Grace = time.Second
+
+// Goroutine is a leaked goroutine and its stack
+type Goroutine = stackdump.Goroutine
+
+// ignored lists functions that mark a goroutine as part of the Go runtime or
+// test harness rather than the code under test
+var ignored = []string{
+	"testing.",
+	"os/signal.",
+	"runtime/trace.",
+}
+
+// Ignore adds functions (or package prefixes such as "net/http.") whose
+// goroutines are never reported
+func Ignore(funcs ...string) {
+	ignored = append(ignored, funcs...)
+}
+
+// isIgnored reports whether g belongs to the runtime or the test harness
+func isIgnored(g Goroutine) bool {
+	if len(g.Frames) == 0 || g.IsRuntime() {
+		return true
+	}
+	for _, prefix := range ignored {
+		if g.HasFunc(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot records which goroutines were running at some point
+type Snapshot struct {
+	ids map[int]bool
+}
+
+// Take records the goroutines running right now
+func Take() Snapshot {
+	s := Snapshot{ids: make(map[int]bool)}
+	for _, g := range stackdump.All() {
+		s.ids[g.ID] = true
+	}
+	return s
+}
+
+// Leaked returns the goroutines started since the snapshot that are still
+// running after Grace
+func (s Snapshot) Leaked() []Goroutine {
+	return find(func(g Goroutine) bool { return !s.ids[g.ID] })
+}
+
+// find waits up to Grace for every goroutine that counts to exit, and
+// returns the ones that did not
+func find(counts func(g Goroutine) bool) []Goroutine {
+	self := stackdump.CurrentID()
+	deadline := time.Now().Add(Grace)
+	for {
+		var leaks []Goroutine
+		for _, g := range stackdump.All() {
+			if g.ID != self && counts(g) && !isIgnored(g) {
+				leaks = append(leaks, g)
+			}
+		}
+		if len(leaks) == 0 || time.Now().After(deadline) {
+			return leaks
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Check runs fn and returns the goroutines it left behind
+func Check(fn func()) []Goroutine {
+	s := Take()
+	fn()
+	return s.Leaked()
+}
+
+// TB is the part of testing.TB the assertions use, so this package does not
+// have to import testing
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Verify fails the test if goroutines started since the snapshot are still
+// running after Grace
+func (s Snapshot) Verify(t TB) {
+	t.Helper()
+	if leaks := s.Leaked(); len(leaks) > 0 {
+		t.Errorf("%s", Report(leaks))
+	}
+}
+
+// CleanupTB is a TB that can run code when the test ends, as testing.TB can
+type CleanupTB interface {
+	TB
+	Cleanup(func())
+}
+
+// Verify takes a snapshot now and fails the test if goroutines started
+// since are still running (after Grace) when the test ends
+func Verify(t CleanupTB) {
+	t.Helper()
+	s := Take()
+	t.Cleanup(func() { s.Verify(t) })
+}
+
+// VerifyNone fails the test if any goroutine other than the caller (and the
+// runtime's and test harness's own) is still running after Grace
+func VerifyNone(t TB) {
+	t.Helper()
+	if leaks := find(func(Goroutine) bool { return true }); len(leaks) > 0 {
+		t.Errorf("%s", Report(leaks))
+	}
+}
+
+// Report describes leaked goroutines: where each one is stuck, who started
+// it, and its full stack
+func Report(leaks []Goroutine) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🚰 %d goroutine(s) leaked:\n", len(leaks))
+	for _, g := range leaks {
+		fmt.Fprintf(&b, "\n   goroutine %d [%s] in %s\n", g.ID, g.State, g.Top().Func)
+		if g.CreatedBy.Func != "" {
+			fmt.Fprintf(&b, "   started by %s\n", g.CreatedBy)
+		}
+		for _, line := range strings.Split(g.Stack, "\n") {
+			fmt.Fprintf(&b, "      %s\n", line)
+		}
+	}
+	return b.String()
+}
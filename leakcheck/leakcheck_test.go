@@ -0,0 +1,74 @@
+package leakcheck
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder is a TB that keeps the failures instead of failing the test
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// shortGrace keeps a test from waiting the full Grace for a leak it expects
+func shortGrace(t *testing.T) {
+	old := Grace
+	Grace = 100 * time.Millisecond
+	t.Cleanup(func() { Grace = old })
+}
+
+func TestLeakIsReported(t *testing.T) {
+	shortGrace(t)
+	release := make(chan struct{})
+	defer close(release)
+
+	snapshot := Take()
+	go func() {
+		<-release // never sent to: this goroutine leaks until the test ends
+	}()
+
+	leaks := snapshot.Leaked()
+	if len(leaks) != 1 {
+		t.Fatalf("got %d leaked goroutines, want 1:\n%s", len(leaks), Report(leaks))
+	}
+	if g := leaks[0]; g.State != "chan receive" || !strings.Contains(g.Stack, "TestLeakIsReported") {
+		t.Errorf("leaked goroutine is %d [%s], want the test's own, stuck on a receive:\n%s", g.ID, g.State, g.Stack)
+	}
+
+	var r recorder
+	snapshot.Verify(&r)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "1 goroutine(s) leaked") {
+		t.Errorf("Verify reported %q, want one leak", r.errors)
+	}
+}
+
+func TestCleanRunReportsNothing(t *testing.T) {
+	Verify(t)
+
+	leaks := Check(func() {
+		done := make(chan struct{})
+		results := make(chan int, 1) // buffered: the sender never waits for a reader
+		go func() {
+			defer close(done)
+			results <- 42
+		}()
+		<-done
+	})
+	if len(leaks) > 0 {
+		t.Errorf("a goroutine that finished was reported:\n%s", Report(leaks))
+	}
+
+	var r recorder
+	Take().Verify(&r)
+	if len(r.errors) > 0 {
+		t.Errorf("Verify reported %q, want nothing", r.errors)
+	}
+}
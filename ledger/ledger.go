@@ -0,0 +1,213 @@
+// Package ledger keeps the books of a pipeline: every item that goes in must
+// come out exactly once, as delivered, dropped, failed or expired.
+//
+// Counters alone can agree by accident - one item lost and another delivered
+// twice still add up. A Ledger follows each item instead:
+//
+//	books := ledger.New()
+//	id := books.In("API-1")         // the item enters the pipeline
+//	books.Move(id, "process")       // ...passes through the stages
+//	books.Out(id, ledger.Delivered) // ...and leaves
+//	if err := books.Check(); err != nil {
+//		fmt.Println(err) // what was lost, duplicated or never entered
+//	}
+//
+// A nil *Ledger is valid and records nothing, so stages can report to it
+// unconditionally.
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Outcome is how an item left the pipeline
+type Outcome int
+
+const (
+	Delivered Outcome = iota // came out the end
+	Dropped                  // shed on purpose, e.g. by load shedding
+	Failed                   // gave up on, e.g. dead-lettered
+	Expired                  // ran out of time
+	numOutcomes
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Delivered:
+		return "delivered"
+	case Dropped:
+		return "dropped"
+	case Failed:
+		return "failed"
+	case Expired:
+		return "expired"
+	default:
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+}
+
+// entry is one item the ledger is following
+type entry struct {
+	id       string
+	stage    string // where it was last seen
+	outcomes []Outcome
+}
+
+// Totals counts items in and out
+type Totals struct {
+	In  int
+	Out [numOutcomes]int
+}
+
+// Balanced reports whether every item that went in came out once
+func (t Totals) Balanced() bool {
+	out := 0
+	for _, n := range t.Out {
+		out += n
+	}
+	return out == t.In
+}
+
+func (t Totals) String() string {
+	return fmt.Sprintf("%d in = %d delivered + %d dropped + %d failed + %d expired",
+		t.In, t.Out[Delivered], t.Out[Dropped], t.Out[Failed], t.Out[Expired])
+}
+
+// Ledger follows items through a pipeline. It is safe for concurrent use.
+type Ledger struct {
+	mu      sync.Mutex
+	next    int
+	open    map[string]*entry // entered since the last Check
+	unknown []string          // outcomes for IDs that were not open
+	batch   Totals            // since the last Check
+	totals  Totals            // over the ledger's whole life
+}
+
+// New creates an empty ledger
+func New() *Ledger {
+	return &Ledger{open: make(map[string]*entry)}
+}
+
+// In records an item entering the pipeline and returns the ID it is known
+// by from now on
+func (l *Ledger) In(name string) string {
+	if l == nil {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.next++
+	id := fmt.Sprintf("%s#%d", name, l.next)
+	l.open[id] = &entry{id: id, stage: "in"}
+	l.batch.In++
+	l.totals.In++
+	return id
+}
+
+// Move records an item reaching a stage, so that a lost item can be traced
+// to where it was last seen
+func (l *Ledger) Move(id, stage string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.open[id]; ok {
+		e.stage = stage
+	}
+}
+
+// Out records an item leaving the pipeline
+func (l *Ledger) Out(id string, outcome Outcome) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.batch.Out[outcome]++
+	l.totals.Out[outcome]++
+	e, ok := l.open[id]
+	if !ok {
+		l.unknown = append(l.unknown, fmt.Sprintf("%q (%s)", id, outcome))
+		return
+	}
+	e.outcomes = append(e.outcomes, outcome)
+}
+
+// Totals returns the counts over the ledger's whole life, across Checks
+func (l *Ledger) Totals() Totals {
+	if l == nil {
+		return Totals{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.totals
+}
+
+// Discrepancy is what Check found wrong
+type Discrepancy struct {
+	Totals     Totals   // since the previous Check
+	Lost       []string // went in and never came out, with the stage they were last seen in
+	Duplicated []string // came out more than once, with each outcome
+	Unknown    []string // came out without going in (or after an earlier Check)
+}
+
+func (d *Discrepancy) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pipeline accounts do not balance: %s", d.Totals)
+	section := func(title string, ids []string) {
+		if len(ids) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):", title, len(ids))
+		for _, id := range ids {
+			b.WriteString("\n  " + id)
+		}
+	}
+	section("lost, never came out", d.Lost)
+	section("came out more than once", d.Duplicated)
+	section("came out without going in", d.Unknown)
+	return b.String()
+}
+
+// Check closes the books on every item that went in since the last Check:
+// it returns a *Discrepancy if any was lost or came out more than once, or
+// if anything came out that never went in. Call it when the pipeline is
+// idle, after the last item has come out. Checked items and their counts
+// are forgotten, so a long-running service can Check after every batch and
+// one bad batch does not fail every Check after it.
+func (l *Ledger) Check() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	d := &Discrepancy{Totals: l.batch, Unknown: l.unknown}
+	for _, e := range l.open {
+		switch len(e.outcomes) {
+		case 0:
+			d.Lost = append(d.Lost, fmt.Sprintf("%s (last seen: %s)", e.id, e.stage))
+		case 1:
+		default:
+			outcomes := make([]string, len(e.outcomes))
+			for i, o := range e.outcomes {
+				outcomes[i] = o.String()
+			}
+			d.Duplicated = append(d.Duplicated, fmt.Sprintf("%s (%s)", e.id, strings.Join(outcomes, ", ")))
+		}
+	}
+	l.open = make(map[string]*entry)
+	l.unknown = nil
+	l.batch = Totals{}
+
+	if len(d.Lost) == 0 && len(d.Duplicated) == 0 && len(d.Unknown) == 0 && d.Totals.Balanced() {
+		return nil
+	}
+	sort.Strings(d.Lost)
+	sort.Strings(d.Duplicated)
+	return d
+}
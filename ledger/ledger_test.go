@@ -0,0 +1,126 @@
+package ledger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// discrepancy asserts that err is a *Discrepancy and returns it
+func discrepancy(t *testing.T, err error) *Discrepancy {
+	t.Helper()
+	var d *Discrepancy
+	if !errors.As(err, &d) {
+		t.Fatalf("Check() = %v, want a *Discrepancy", err)
+	}
+	return d
+}
+
+func TestBalanced(t *testing.T) {
+	books := New()
+	for i, outcome := range []Outcome{Delivered, Dropped, Failed, Expired, Delivered} {
+		id := books.In("API-1")
+		books.Move(id, "process")
+		books.Out(id, outcome)
+		if i == 0 && !strings.HasPrefix(id, "API-1#") {
+			t.Errorf("In(API-1) = %q, want an ID naming the source", id)
+		}
+	}
+	if err := books.Check(); err != nil {
+		t.Fatalf("Check() = %v, want nil", err)
+	}
+	want := Totals{In: 5, Out: [numOutcomes]int{Delivered: 2, Dropped: 1, Failed: 1, Expired: 1}}
+	if got := books.Totals(); got != want {
+		t.Errorf("Totals() = %v, want %v", got, want)
+	}
+}
+
+func TestLost(t *testing.T) {
+	books := New()
+	books.Out(books.In("API-1"), Delivered)
+	lost := books.In("Database-1")
+	books.Move(lost, "fetched")
+	books.Move(lost, "queued")
+
+	d := discrepancy(t, books.Check())
+	if len(d.Lost) != 1 || d.Lost[0] != lost+" (last seen: queued)" {
+		t.Errorf("Lost = %q, want %s last seen queued", d.Lost, lost)
+	}
+	if d.Totals.Balanced() {
+		t.Errorf("Totals %v balanced with an item lost", d.Totals)
+	}
+	if !strings.Contains(d.Error(), "lost, never came out (1)") {
+		t.Errorf("Error() = %q, want it to list the lost item", d.Error())
+	}
+}
+
+func TestDuplicated(t *testing.T) {
+	books := New()
+	id := books.In("API-2")
+	books.Out(id, Delivered)
+	books.Out(id, Failed)
+	// A lost item makes up for the duplicate, so only the ledger can tell
+	books.In("API-3")
+
+	d := discrepancy(t, books.Check())
+	if !d.Totals.Balanced() {
+		t.Errorf("Totals %v, want them to add up by accident", d.Totals)
+	}
+	if len(d.Duplicated) != 1 || d.Duplicated[0] != id+" (delivered, failed)" {
+		t.Errorf("Duplicated = %q, want %s with both outcomes", d.Duplicated, id)
+	}
+	if len(d.Lost) != 1 {
+		t.Errorf("Lost = %q, want the API-3 item", d.Lost)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	books := New()
+	books.Out("API-1#99", Delivered)
+
+	d := discrepancy(t, books.Check())
+	if len(d.Unknown) != 1 || d.Unknown[0] != `"API-1#99" (delivered)` {
+		t.Errorf("Unknown = %q, want the unknown ID", d.Unknown)
+	}
+}
+
+func TestNilLedger(t *testing.T) {
+	var books *Ledger
+	id := books.In("API-1")
+	books.Move(id, "process")
+	books.Out(id, Delivered)
+	if err := books.Check(); err != nil {
+		t.Errorf("Check() = %v on a nil ledger, want nil", err)
+	}
+	if got := books.Totals(); got != (Totals{}) {
+		t.Errorf("Totals() = %v on a nil ledger, want zero", got)
+	}
+}
+
+func TestCheckAfterCheck(t *testing.T) {
+	books := New()
+
+	// Batch 1 loses an item
+	books.Out(books.In("API-1"), Delivered)
+	books.In("API-2")
+	if d := discrepancy(t, books.Check()); d.Totals.In != 2 {
+		t.Errorf("first Check counted %d in, want 2", d.Totals.In)
+	}
+
+	// Batch 2 balances, and must not inherit batch 1's books
+	books.Out(books.In("API-3"), Failed)
+	if err := books.Check(); err != nil {
+		t.Fatalf("second Check() = %v, want nil", err)
+	}
+
+	// An item checked in batch 1 is unknown to batch 3
+	books.Out("API-2#2", Delivered)
+	if d := discrepancy(t, books.Check()); len(d.Unknown) != 1 || d.Totals.In != 0 {
+		t.Errorf("third Check = %v, want only the late outcome", d)
+	}
+
+	want := Totals{In: 3, Out: [numOutcomes]int{Delivered: 2, Failed: 1}}
+	if got := books.Totals(); got != want {
+		t.Errorf("Totals() = %v, want the lifetime %v", got, want)
+	}
+}
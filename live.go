@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang-concurrency-demo/livefeed"
+)
+
+// ============================================================================
+// LIVE VIEW: A browser page animating the pipeline while it runs
+// ============================================================================
+
+// startLiveView serves the live page on addr (-live) and waits until a
+// browser has opened it, so that it sees the demo from the start. If ctx
+// ends first, it stops the server and returns an error. The returned func
+// ends the page's event stream and stops the server. An empty addr does
+// nothing.
+func startLiveView(ctx context.Context, addr string) (stop func(), err error) {
+	if addr == "" {
+		return func() {}, nil
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	live = livefeed.New()
+	server := &http.Server{Handler: live.Handler()}
+	go server.Serve(listener)
+
+	fmt.Printf("📺 Live view on http://%s - the demo starts once a browser has it open\n", listener.Addr())
+	stop = func() {
+		live.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}
+	if err := live.WaitForViewer(ctx); err != nil {
+		stop()
+		return nil, fmt.Errorf("no browser connected: %w", err)
+	}
+	fmt.Println("   Browser connected")
+	return stop, nil
+}
@@ -0,0 +1,194 @@
+// Package livefeed streams what a pipeline is doing to a browser, where an
+// embedded page animates it: fetch goroutines, the channel between the
+// stages, the worker pool and the output, with every item moving through
+// them as it happens.
+//
+//	feed := livefeed.New()
+//	go http.ListenAndServe("localhost:8081", feed.Handler())
+//	feed.WaitForViewer(ctx) // until someone opens http://localhost:8081
+//	...
+//	feed.Emit(livefeed.Event{Kind: livefeed.Fetch, Item: id, Source: "API-1"})
+//
+// The page is served from the binary itself (embed) and follows the events
+// over Server-Sent Events. A nil *Feed is valid and sends nothing, so the
+// pipeline can emit unconditionally.
+package livefeed
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Kind is what happened to an item (or the pipeline)
+type Kind string
+
+const (
+	Cycle     Kind = "cycle"     // a pass over the sources starts (Detail: the cycle number)
+	Fetch     Kind = "fetch"     // a fetch goroutine starts on Source
+	Fetched   Kind = "fetched"   // the fetch answered (Detail: how, e.g. "cache hit")
+	Queued    Kind = "queued"    // the item went into the channel to the workers
+	Process   Kind = "process"   // Worker took the item
+	Processed Kind = "processed" // Worker finished it
+	Output    Kind = "output"    // the output stage received it
+	Failed    Kind = "failed"    // given up on (Detail: why); Worker is 0 if the fetch failed
+	Expired   Kind = "expired"   // ran out of time budget
+	Done      Kind = "done"      // the demo has finished
+)
+
+// Event is one thing that happened. Item identifies the item across events.
+type Event struct {
+	At     int64  `json:"at"` // milliseconds since the feed was created
+	Kind   Kind   `json:"kind"`
+	Item   string `json:"item,omitempty"`
+	Source string `json:"source,omitempty"`
+	Worker int    `json:"worker,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+//go:embed index.html
+var page []byte
+
+// Feed fans events out to every connected browser. It is safe for
+// concurrent use.
+type Feed struct {
+	start time.Time
+
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+	done        chan struct{} // closed by Close
+	viewer      chan struct{} // closed when the first browser connects
+}
+
+// New creates a feed with no viewers
+func New() *Feed {
+	return &Feed{
+		start:       time.Now(),
+		subscribers: make(map[chan Event]struct{}),
+		done:        make(chan struct{}),
+		viewer:      make(chan struct{}),
+	}
+}
+
+// Emit sends an event to every viewer. A viewer that is not keeping up
+// misses events rather than slowing the pipeline down.
+func (f *Feed) Emit(e Event) {
+	if f == nil {
+		return
+	}
+	e.At = time.Since(f.start).Milliseconds()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for events := range f.subscribers {
+		select {
+		case events <- e:
+		default:
+		}
+	}
+}
+
+// WaitForViewer blocks until a browser is following the feed, or ctx ends
+func (f *Feed) WaitForViewer(ctx context.Context) error {
+	if f == nil {
+		return nil
+	}
+	select {
+	case <-f.viewer:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close ends every event stream
+func (f *Feed) Close() {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.done)
+	}
+}
+
+// Handler serves the page at / and the events at /events
+func (f *Feed) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+	mux.HandleFunc("/events", f.serveEvents)
+	return mux
+}
+
+// serveEvents streams events as Server-Sent Events until the browser goes
+// away or the feed is closed
+func (f *Feed) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	events := make(chan Event, 256)
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		http.Error(w, "the demo has finished", http.StatusGone)
+		return
+	}
+	f.subscribers[events] = struct{}{}
+	select {
+	case <-f.viewer:
+	default:
+		close(f.viewer)
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		delete(f.subscribers, events)
+		f.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	send := func(e Event) {
+		data, _ := json.Marshal(e)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+	for {
+		select {
+		case e := <-events:
+			send(e)
+		case <-r.Context().Done():
+			return
+		case <-f.done:
+			// Pass on what was emitted before Close, then say goodbye
+			for drained := false; !drained; {
+				select {
+				case e := <-events:
+					send(e)
+				default:
+					drained = true
+				}
+			}
+			send(Event{At: time.Since(f.start).Milliseconds(), Kind: Done})
+			return
+		}
+	}
+}
@@ -1,10 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"math"
 	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang-concurrency-demo/faults"
+	"golang-concurrency-demo/leakcheck"
+	"golang-concurrency-demo/ledger"
+	"golang-concurrency-demo/livefeed"
+	"golang-concurrency-demo/timeline"
+	"golang-concurrency-demo/watchdog"
 )
 
 // Worker represents a worker that processes jobs
@@ -13,12 +30,13 @@ type Worker struct {
 }
 
 // Process simulates work being done by a worker
-func (w Worker) Process(job int, wg *sync.WaitGroup, results chan<- string) {
-	defer wg.Done()
+func (w Worker) Process(job int, results chan<- string) {
+	span := recorder.Start(fmt.Sprintf("Worker %d", w.id), fmt.Sprintf("job %d", job))
 	
 	// Simulate some work with random duration
 	processingTime := time.Duration(rand.Intn(1000)) * time.Millisecond
 	time.Sleep(processingTime)
+	span.End()
 	
 	result := fmt.Sprintf("Worker %d processed job %d in %v", w.id, job, processingTime)
 	results <- result
@@ -55,9 +73,12 @@ func counter(name string, iterations int, mu *sync.Mutex, sharedCounter *int, wg
 // pipelineStage1 - first stage of pipeline
 func pipelineStage1(input <-chan int, output chan<- int) {
 	for num := range input {
+		region := traceRegion("pipelineStage1")
 		// Square the number
 		result := num * num
 		output <- result
+		region.End()
+		watch.Beat("pipelineStage1")
 	}
 	close(output)
 }
@@ -65,9 +86,12 @@ func pipelineStage1(input <-chan int, output chan<- int) {
 // pipelineStage2 - second stage of pipeline
 func pipelineStage2(input <-chan int, output chan<- string) {
 	for num := range input {
+		region := traceRegion("pipelineStage2")
 		// Convert to string with formatting
 		result := fmt.Sprintf("Result: %d", num)
 		output <- result
+		region.End()
+		watch.Beat("pipelineStage2")
 	}
 	close(output)
 }
@@ -76,22 +100,31 @@ func pipelineStage2(input <-chan int, output chan<- string) {
 func demonstrateWorkerPool() {
 	fmt.Println("\n=== Worker Pool Demo ===")
 	
-	numWorkers := 5
 	numJobs := 15
 	
-	var wg sync.WaitGroup
+	jobs := make(chan int, numJobs)
 	results := make(chan string, numJobs)
 	
-	// Launch workers
+	// Between 1 and 5 workers, as many as the queue needs (AUTOSCALING)
+	pool := NewAutoscalingPool(PoolConfig{
+		MinWorkers:       1,
+		MaxWorkers:       5,
+		TargetQueueDelay: time.Second,
+		ScaleDownAfter:   300 * time.Millisecond,
+		OnScale:          func(e ScaleEvent) { fmt.Printf("   %v\n", e) },
+	}, jobs, func(workerID int, job int) {
+		Worker{id: workerID}.Process(job, results)
+	})
+	pool.Start()
+	
 	for i := 1; i <= numJobs; i++ {
-		wg.Add(1)
-		worker := Worker{id: (i % numWorkers) + 1}
-		go worker.Process(i, &wg, results)
+		jobs <- i
 	}
+	close(jobs)
 	
 	// Wait for all workers to complete and close results channel
 	go func() {
-		wg.Wait()
+		pool.Wait()
 		close(results)
 	}()
 	
@@ -110,11 +143,18 @@ func demonstrateAsyncFetching() {
 	var wg sync.WaitGroup
 	dataChan := make(chan string, len(sources))
 	
+	// Requests for a source already being fetched share that fetch
+	group := NewFlightGroup[string]()
+	
 	// Launch async fetch operations
 	startTime := time.Now()
 	for _, source := range sources {
 		wg.Add(1)
-		go fetchData(source, &wg, dataChan)
+		go func(src string) {
+			defer wg.Done()
+			data, _, _ := group.Do(src, func() (string, error) { return fetchSource(src), nil })
+			dataChan <- data
+		}(source)
 	}
 	
 	// Wait for all fetches to complete
@@ -129,6 +169,9 @@ func demonstrateAsyncFetching() {
 	}
 	
 	fmt.Printf("Total time: %v\n", time.Since(startTime))
+	if n := group.Deduplicated(); n > 0 {
+		fmt.Printf("Coalesced requests: %d\n", n)
+	}
 }
 
 // demonstrateMutex shows thread-safe counter using mutex
@@ -159,6 +202,9 @@ func demonstratePipeline() {
 	stage1Input := make(chan int)
 	stage1Output := make(chan int)
 	stage2Output := make(chan string)
+	watch.Channel("stage1Input", stage1Input)
+	watch.Channel("stage1Output", stage1Output)
+	watch.Channel("stage2Output", stage2Output)
 	
 	// Start pipeline stages
 	go pipelineStage1(stage1Input, stage1Output)
@@ -215,9 +261,29 @@ func demonstrateSelect() {
 
 // Simulated API data
 type APIResponse struct {
-	Source string
-	Data   string
-	Time   time.Duration
+	Source   string
+	Data     string
+	Time     time.Duration
+	Priority Priority
+	Deadline time.Time `json:"-"` // when the item must be done by; zero = no budget
+	ID       string    `json:"-"` // how the ledger knows the item (see accounts)
+	Letter   int       `json:"-"` // the dead letter it is a replay of (see TakeForReplay); 0 = none
+}
+
+// Context returns a context that ends at the item's deadline, if it has one
+func (r *APIResponse) Context() (context.Context, context.CancelFunc) {
+	if r.Deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), r.Deadline)
+}
+
+// Remaining returns how much of the item's time budget is left
+func (r *APIResponse) Remaining() time.Duration {
+	if r.Deadline.IsZero() {
+		return math.MaxInt64
+	}
+	return time.Until(r.Deadline)
 }
 
 // Processed result
@@ -226,123 +292,447 @@ type ProcessedData struct {
 	Original  string
 	Processed string
 	Source    string
+	ItemID    string // the item's ledger ID (see accounts)
 }
 
-// Statistics (protected by mutex)
+// Statistics: the totals are Counters (see counters.go, picked with -stats),
+// the per-source map is protected by mutex
 type Stats struct {
-	mu           sync.Mutex
-	totalFetched int
-	totalProcessed int
-	errors       int
+	totalFetched   Counter
+	totalProcessed Counter
+	errors         Counter
+	deadLettered   Counter
+	deduplicated   Counter
+	hedged         Counter
+	rejected       Counter
+	expired        Counter
+	
+	// How the result cache answered fetches
+	cacheHits   Counter
+	cacheStale  Counter
+	cacheMisses Counter
+	
+	mu        sync.Mutex
+	started   time.Time
+	perSource map[string]*sourceStats
+}
+
+// sourceStats tracks how one source moved through the workers
+type sourceStats struct {
+	processed int
+	last      time.Time // when its latest item finished
+}
+
+// newStatsCounter creates the counters Stats keeps its totals in
+var newStatsCounter = NewMutexCounter
+
+// NewStats creates statistics whose throughput is measured from now.
+// Close them when done.
+func NewStats() *Stats {
+	return &Stats{
+		totalFetched:   newStatsCounter(),
+		totalProcessed: newStatsCounter(),
+		errors:         newStatsCounter(),
+		deadLettered:   newStatsCounter(),
+		deduplicated:   newStatsCounter(),
+		hedged:         newStatsCounter(),
+		rejected:       newStatsCounter(),
+		expired:        newStatsCounter(),
+		cacheHits:      newStatsCounter(),
+		cacheStale:     newStatsCounter(),
+		cacheMisses:    newStatsCounter(),
+		started:        time.Now(),
+		perSource:      make(map[string]*sourceStats),
+	}
+}
+
+// Close stops the counters' goroutines, if they have any
+func (s *Stats) Close() {
+	for _, c := range []Counter{s.totalFetched, s.totalProcessed, s.errors, s.deadLettered, s.deduplicated,
+		s.hedged, s.rejected, s.expired, s.cacheHits, s.cacheStale, s.cacheMisses} {
+		closeCounter(c)
+	}
 }
 
 func (s *Stats) IncrementFetched() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.totalFetched++
+	s.totalFetched.Add(1)
 }
 
-func (s *Stats) IncrementProcessed() {
+func (s *Stats) IncrementProcessed(source string) {
+	s.totalProcessed.Add(1)
+	
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.totalProcessed++
+	ss, ok := s.perSource[source]
+	if !ok {
+		ss = &sourceStats{}
+		s.perSource[source] = ss
+	}
+	ss.processed++
+	ss.last = time.Now()
 }
 
 func (s *Stats) IncrementErrors() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.errors++
+	s.errors.Add(1)
+}
+
+func (s *Stats) IncrementDeadLettered() {
+	s.deadLettered.Add(1)
+}
+
+func (s *Stats) IncrementDeduplicated() {
+	s.deduplicated.Add(1)
+}
+
+func (s *Stats) IncrementHedged() {
+	s.hedged.Add(1)
+}
+
+func (s *Stats) IncrementRejected() {
+	s.rejected.Add(1)
+}
+
+func (s *Stats) IncrementExpired() {
+	s.expired.Add(1)
+}
+
+func (s *Stats) IncrementCache(result CacheResult) {
+	switch result {
+	case CacheHit:
+		s.cacheHits.Add(1)
+	case CacheStale:
+		s.cacheStale.Add(1)
+	default:
+		s.cacheMisses.Add(1)
+	}
 }
 
 func (s *Stats) Print() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	fmt.Printf("\n📊 Final Statistics:\n")
-	fmt.Printf("   Fetched: %d | Processed: %d | Errors: %d\n", 
-		s.totalFetched, s.totalProcessed, s.errors)
+	fmt.Printf("   Fetched: %d | Processed: %d | Errors: %d | Dead-lettered: %d\n", 
+		s.totalFetched.Load(), s.totalProcessed.Load(), s.errors.Load(), s.deadLettered.Load())
+	if deduplicated := s.deduplicated.Load(); deduplicated > 0 {
+		fmt.Printf("   Coalesced fetches: %d (served by a fetch already in flight)\n", deduplicated)
+	}
+	if hedged := s.hedged.Load(); hedged > 0 {
+		fmt.Printf("   Hedged fetches: %d (a second attempt raced a slow first one)\n", hedged)
+	}
+	if rejected := s.rejected.Load(); rejected > 0 {
+		fmt.Printf("   Rejected by bulkheads: %d\n", rejected)
+	}
+	if expired := s.expired.Load(); expired > 0 {
+		fmt.Printf("   Expired: %d (ran out of time budget before they could finish)\n", expired)
+	}
+	if hits, stale := s.cacheHits.Load(), s.cacheStale.Load(); hits+stale > 0 {
+		fmt.Printf("   Cache: %d hits | %d stale (refreshed in the background) | %d misses\n",
+			hits, stale, s.cacheMisses.Load())
+	}
+	
+	// Per-source throughput (items/s until its last item finished)
+	// shows whether one source hogged the workers
+	sources := make([]string, 0, len(s.perSource))
+	for source := range s.perSource {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		ss := s.perSource[source]
+		fmt.Printf("   %-12s %3d processed (%.1f/s)\n", source+":", ss.processed, 
+			float64(ss.processed)/ss.last.Sub(s.started).Seconds())
+	}
+}
+
+// How long a single fetch may take before it counts as failed
+const fetchTimeout = 1 * time.Second
+
+// Waiting this long raises a queued item's priority by one class
+const priorityAging = 500 * time.Millisecond
+
+// How long a fetch waits for a slot in its group's bulkhead before it is rejected
+const bulkheadWait = 300 * time.Millisecond
+
+// A worker skips an item with less than this left of its budget: processing
+// it could not finish in time anyway
+const minProcessBudget = 50 * time.Millisecond
+
+// errBudgetSpent means an item ran out of time while it was being retried
+var errBudgetSpent = errors.New("time budget spent")
+
+// itemDeadline is when an item whose fetch started at start must be done by:
+// -item-budget later, or the pipeline deadline if that comes first. Zero
+// means no limit.
+func itemDeadline(start, pipeline time.Time) time.Time {
+	deadline := pipeline
+	if *itemBudget > 0 {
+		if budget := start.Add(*itemBudget); deadline.IsZero() || budget.Before(deadline) {
+			deadline = budget
+		}
+	}
+	return deadline
 }
 
+// How many times a worker tries to process an item before dead-lettering it
+const maxProcessAttempts = 3
+
 // Stage 1: Async fetching with timeout (using select)
 func fetchWithTimeout(source string, timeout time.Duration, stats *Stats) (*APIResponse, error) {
 	responseChan := make(chan *APIResponse, 1)
 	errorChan := make(chan error, 1)
+	span := recorder.Start("fetch "+source, source)
 	
-	// Simulate async fetch
+	// Cancelling stops attempts still running once we stop waiting for them
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	
+	// Simulate async fetch, hedged with a second attempt if it is slow (-hedge)
 	go func() {
-		fetchTime := time.Duration(rand.Intn(800)) * time.Millisecond
-		time.Sleep(fetchTime)
-		
-		responseChan <- &APIResponse{
-			Source: source,
-			Data:   fmt.Sprintf("data-from-%s", source),
-			Time:   fetchTime,
+		response, hedged, err := hedge(ctx, *hedgeDelay, func(ctx context.Context) (*APIResponse, error) {
+			return fetchAttempt(ctx, source)
+		})
+		if hedged {
+			stats.IncrementHedged()
 		}
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		responseChan <- response
 	}()
 	
 	// Use SELECT to handle timeout
 	select {
 	case response := <-responseChan:
+		span.End()
 		stats.IncrementFetched()
 		return response, nil
 	case err := <-errorChan:
+		span.EndAs(timeline.KindError)
 		stats.IncrementErrors()
 		return nil, err
 	case <-time.After(timeout):
+		span.EndAs(timeline.KindTimeout)
 		stats.IncrementErrors()
 		return nil, fmt.Errorf("timeout fetching from %s", source)
 	}
 }
 
+// Processor turns fetched data into its processed form.
+// Returning an error marks the attempt as failed.
+// See processors.go for handlers that do real work.
+type Processor interface {
+	Process(job *APIResponse) (string, error)
+}
+
+// ProcessFunc lets an ordinary function be used as a Processor
+type ProcessFunc func(job *APIResponse) (string, error)
+
+func (f ProcessFunc) Process(job *APIResponse) (string, error) {
+	return f(job)
+}
+
+// Chance (0-1) that a simulated processing attempt fails, set with -fail-rate
+var processingFailureRate = 0.1
+
+// simulateProcessing is the default processor: random sleep, occasional failure
+func simulateProcessing(job *APIResponse) (string, error) {
+	time.Sleep(time.Duration(rand.Intn(200)) * time.Millisecond)
+	
+	if rand.Float64() < processingFailureRate {
+		return "", fmt.Errorf("corrupt payload from %s", job.Source)
+	}
+	if full := "data-from-" + job.Source; len(job.Data) < len(full) && strings.HasPrefix(full, job.Data) {
+		return "", permanent(fmt.Errorf("incomplete payload from %s: %q", job.Source, job.Data))
+	}
+	return fmt.Sprintf("PROCESSED[%s]", job.Data), nil
+}
+
+// processSafely runs one attempt, turning a panic into an error so that one
+// bad item cannot take its worker down with it
+func processSafely(processor Processor, job *APIResponse) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("processor panicked: %v", r)
+		}
+	}()
+	return processor.Process(job)
+}
+
+// withFaults makes processor misbehave the way -faults says target should.
+// A fault waits at most fetchTimeout, or the item's budget if that is
+// shorter: an item without a budget would otherwise hang its worker for good.
+func withFaults(processor Processor, target string) Processor {
+	if injector == nil {
+		return processor
+	}
+	return ProcessFunc(func(job *APIResponse) (string, error) {
+		fault := injector.Roll(target)
+		budget, cancel := job.Context()
+		defer cancel()
+		ctx, stop := context.WithTimeout(budget, fetchTimeout)
+		defer stop()
+		if err := fault.Apply(ctx); err != nil {
+			return "", err
+		}
+		result, err := processor.Process(job)
+		return fault.Cut(result), err
+	})
+}
+
+// permanentError marks a failure that trying again cannot fix, such as
+// input the processor does not accept
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// permanent wraps err so that processWithRetry does not retry it
+func permanent(err error) error {
+	return permanentError{err}
+}
+
+// isPermanent reports whether err, or an error it wraps, is permanent
+func isPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// processWithRetry tries process up to maxProcessAttempts times with a short backoff,
+// giving up early (errBudgetSpent) when the item has no time left for another try,
+// and at once when the error is permanent
+func processWithRetry(job *APIResponse, processor Processor) (result string, attempts int, firstFailure time.Time, err error) {
+	for attempts = 1; attempts <= maxProcessAttempts; attempts++ {
+		result, err = processSafely(processor, job)
+		if err == nil {
+			return result, attempts, firstFailure, nil
+		}
+		if firstFailure.IsZero() {
+			firstFailure = time.Now()
+		}
+		if isPermanent(err) {
+			return "", attempts, firstFailure, err
+		}
+		if attempts < maxProcessAttempts {
+			backoff := time.Duration(attempts*50) * time.Millisecond
+			if job.Remaining() < backoff+minProcessBudget {
+				return "", attempts, firstFailure, errBudgetSpent
+			}
+			time.Sleep(backoff)
+		}
+	}
+	return "", maxProcessAttempts, firstFailure, err
+}
+
 // Stage 2: Worker pool for processing
+// Items that keep failing are routed to the dead-letter queue instead of results
 func processingWorker(id int, jobs <-chan *APIResponse, results chan<- ProcessedData, 
-	stats *Stats, wg *sync.WaitGroup) {
+	processor Processor, dlq *DeadLetterQueue, stats *Stats, wg *sync.WaitGroup) {
 	defer wg.Done()
+	processor = withFaults(processor, fmt.Sprintf("Worker-%d", id))
 	
 	for job := range jobs {
-		// Simulate processing
-		time.Sleep(time.Duration(rand.Intn(200)) * time.Millisecond)
-		
-		result := ProcessedData{
-			ID:        id,
-			Original:  job.Data,
-			Processed: fmt.Sprintf("PROCESSED[%s]", job.Data),
-			Source:    job.Source,
-		}
-		
-		stats.IncrementProcessed()
-		results <- result
+		processItem(id, job, results, processor, dlq, stats)
+	}
+}
+
+// processItem is one worker's handling of one item; processingWorker calls
+// it for each item it receives, the integrated demo's autoscaling pool for
+// each item it hands a worker
+func processItem(id int, job *APIResponse, results chan<- ProcessedData, 
+	processor Processor, dlq *DeadLetterQueue, stats *Stats) {
+	// Items that cannot finish in time are skipped, not processed (DEADLINES)
+	if left := job.Remaining(); left < minProcessBudget {
+		fmt.Printf("   ⌛ Worker-%d: skipping %s, %v left of its budget\n",
+			id, job.Source, left.Round(time.Millisecond))
+		stats.IncrementExpired()
+		accounts.Out(job.ID, ledger.Expired)
+		live.Emit(livefeed.Event{Kind: livefeed.Expired, Item: job.ID, Source: job.Source, Worker: id})
+		return
+	}
+	accounts.Move(job.ID, "process")
+	live.Emit(livefeed.Event{Kind: livefeed.Process, Item: job.ID, Source: job.Source, Worker: id})
+	
+	region := traceRegion("processingWorker")
+	span := recorder.Start(fmt.Sprintf("Worker-%d", id), job.Source)
+	processed, attempts, firstFailure, err := processWithRetry(job, processor)
+	if errors.Is(err, errBudgetSpent) {
+		span.EndAs(timeline.KindTimeout)
+		region.End()
+		fmt.Printf("   ⌛ Worker-%d: %s ran out of time after %d attempt(s)\n", id, job.Source, attempts)
+		stats.IncrementExpired()
+		accounts.Out(job.ID, ledger.Expired)
+		live.Emit(livefeed.Event{Kind: livefeed.Expired, Item: job.ID, Source: job.Source, Worker: id})
+		return
+	}
+	if err != nil {
+		span.EndAs(timeline.KindError)
+		region.End()
+		fmt.Printf("   ☠️  Worker-%d: giving up on %s after %d attempts: %v\n",
+			id, job.Source, attempts, err)
+		stats.IncrementDeadLettered()
+		accounts.Out(job.ID, ledger.Failed)
+		live.Emit(livefeed.Event{Kind: livefeed.Failed, Item: job.ID, Source: job.Source, Worker: id, Detail: err.Error()})
+		dlq.Add(DeadLetter{
+			Stage:        stageProcess,
+			Source:       job.Source,
+			Item:         job,
+			Error:        err.Error(),
+			Attempts:     attempts,
+			FirstFailure: firstFailure,
+			LastFailure:  time.Now(),
+			replayOf:     job.Letter,
+		})
+		return
 	}
+	
+	result := ProcessedData{
+		ID:        id,
+		Original:  job.Data,
+		Processed: processed,
+		Source:    job.Source,
+		ItemID:    job.ID,
+	}
+	
+	span.End()
+	stats.IncrementProcessed(job.Source)
+	accounts.Move(job.ID, "output")
+	live.Emit(livefeed.Event{Kind: livefeed.Processed, Item: job.ID, Source: job.Source, Worker: id})
+	results <- result
+	region.End()
+	watch.Beat("processingWorker")
 }
 
 // Stage 3: Pipeline for final output
-func outputPipeline(results <-chan ProcessedData, done chan<- bool) {
+func outputPipeline(results <-chan ProcessedData, done chan<- int) {
 	fmt.Println("\n📦 Processing Results:")
 	count := 0
 	for result := range results {
+		region := traceRegion("outputPipeline")
 		fmt.Printf("   Worker-%d: %s (from %s)\n", 
 			result.ID, result.Processed, result.Source)
+		accounts.Out(result.ItemID, ledger.Delivered)
+		live.Emit(livefeed.Event{Kind: livefeed.Output, Item: result.ItemID, Source: result.Source, Worker: result.ID})
+		region.End()
 		count++
+		watch.Beat("outputPipeline")
 	}
 	fmt.Printf("   Total results: %d\n", count)
-	done <- true
+	done <- count
 }
 
-// INTEGRATED DEMONSTRATION
-func demonstrateIntegrated() {
-	fmt.Println("\n=== 🎯 INTEGRATED DEMO: All Patterns Combined ===")
-	fmt.Println("Scenario: Fetch data from APIs, process with workers, output via pipeline")
-	fmt.Println()
-	
-	// Initialize statistics (MUTEX pattern)
-	stats := &Stats{}
-	
-	// Data sources
-	sources := []string{"API-1", "API-2", "API-3", "API-4", "API-5"}
-	
+// runIntegratedCycle takes every source once through fetch → worker pool → output
+// and returns how many results came out
+func runIntegratedCycle(sources []string, priorities map[string]Priority, cache *TTLCache[*APIResponse],
+	bulkheads *Bulkheads, processor Processor, dlq *DeadLetterQueue, stats *Stats) int {
 	// Channels for pipeline
 	fetchedData := make(chan *APIResponse, len(sources))
 	processedData := make(chan ProcessedData, len(sources))
-	done := make(chan bool)
+	done := make(chan int)
+	watch.Channel("fetchedData", fetchedData)
+	watch.Channel("processedData", processedData)
 	
 	// ========================================
 	// STAGE 1: ASYNC FETCHING with SELECT
@@ -350,46 +740,150 @@ func demonstrateIntegrated() {
 	fmt.Println("🌐 Stage 1: Fetching from multiple APIs concurrently...")
 	var fetchWg sync.WaitGroup
 	
+	// Items fetched but not yet taken by a worker: the queue the worker
+	// pool scales on (AUTOSCALING)
+	var waiting atomic.Int64
+	
+	// A source listed twice is fetched once (REQUEST COALESCING)
+	fetches := NewFlightGroup[*APIResponse]()
+	
+	// The whole cycle must finish by -deadline, and each item within
+	// -item-budget of its fetch starting (DEADLINES)
+	var cycleDeadline time.Time
+	if *pipelineDeadline > 0 {
+		cycleDeadline = time.Now().Add(*pipelineDeadline)
+		fmt.Printf("   ⏱️  Pipeline deadline: %v\n", *pipelineDeadline)
+	}
+	
 	for _, source := range sources {
 		fetchWg.Add(1)
 		go func(src string) {
 			defer fetchWg.Done()
+			itemID := accounts.In(src)
+			live.Emit(livefeed.Event{Kind: livefeed.Fetch, Item: itemID, Source: src})
+			
+			deadline := itemDeadline(time.Now(), cycleDeadline)
+			timeout := fetchTimeout
+			if !deadline.IsZero() {
+				left := time.Until(deadline)
+				if left < minProcessBudget {
+					fmt.Printf("   ⌛ Skipping %s, %v left of its budget\n", src, left.Round(time.Millisecond))
+					stats.IncrementExpired()
+					accounts.Out(itemID, ledger.Expired)
+					live.Emit(livefeed.Event{Kind: livefeed.Expired, Item: itemID, Source: src})
+					return
+				}
+				// Leave time to process what was fetched
+				if left -= minProcessBudget; left < timeout {
+					timeout = left
+				}
+			}
 			
 			// Fetch with 1 second timeout (SELECT pattern)
-			response, err := fetchWithTimeout(src, 1*time.Second, stats)
+			region := traceRegion("fetchWithTimeout")
+			response, cached, err := cache.Get(src, func() (*APIResponse, error) {
+				response, err, shared := fetches.Do(src, func() (*APIResponse, error) {
+					// A group that hangs only ties up its own slots (BULKHEAD)
+					release, err := bulkheads.For(src).Enter()
+					if err != nil {
+						stats.IncrementRejected()
+						return nil, err
+					}
+					defer release()
+					return fetchWithTimeout(src, timeout, stats)
+				})
+				if shared {
+					stats.IncrementDeduplicated()
+				}
+				return response, err
+			})
+			region.End()
+			stats.IncrementCache(cached)
+			watch.Beat("fetchWithTimeout")
+			if err != nil && !deadline.IsZero() && time.Until(deadline) < minProcessBudget {
+				// Cut short by its budget, not broken: retrying it later is pointless
+				fmt.Printf("   ⌛ %s: out of time fetching (%v)\n", src, err)
+				stats.IncrementExpired()
+				accounts.Out(itemID, ledger.Expired)
+				live.Emit(livefeed.Event{Kind: livefeed.Expired, Item: itemID, Source: src})
+				return
+			}
 			if err != nil {
 				fmt.Printf("   ⚠️  Error: %v\n", err)
+				stats.IncrementDeadLettered()
+				accounts.Out(itemID, ledger.Failed)
+				live.Emit(livefeed.Event{Kind: livefeed.Failed, Item: itemID, Source: src, Detail: err.Error()})
+				dlq.Add(fetchDeadLetter(src, err, time.Now()))
 				return
 			}
 			
-			fmt.Printf("   ✓ Fetched from %s in %v\n", response.Source, response.Time)
+			// Coalesced callers share the response, so each gets its own copy
+			item := *response
+			response = &item
+			response.Priority = priorities[src]
+			response.Deadline = deadline
+			response.ID = itemID
+			switch cached {
+			case CacheHit:
+				fmt.Printf("   📦 %s from cache\n", response.Source)
+			case CacheStale:
+				fmt.Printf("   📦 %s from cache (stale, refreshing in the background)\n", response.Source)
+			default:
+				fmt.Printf("   ✓ Fetched from %s in %v\n", response.Source, response.Time)
+			}
+			live.Emit(livefeed.Event{Kind: livefeed.Fetched, Item: itemID, Source: src, Detail: "cache " + cached.String()})
+			accounts.Move(itemID, "queued")
+			live.Emit(livefeed.Event{Kind: livefeed.Queued, Item: itemID, Source: src})
+			waiting.Add(1)
 			fetchedData <- response
 		}(source)
 	}
 	
 	// Wait for all fetches, then close channel
+	// Print before closing, so the message cannot outlive the cycle
 	go func() {
 		fetchWg.Wait()
+		fmt.Println("   All fetches complete!")
+		fmt.Println()
 		close(fetchedData)
-		fmt.Println("   All fetches complete!\n")
 	}()
 	
 	// ========================================
 	// STAGE 2: WORKER POOL for processing
 	// ========================================
 	fmt.Println("⚙️  Stage 2: Processing data with worker pool...")
-	var processWg sync.WaitGroup
-	numWorkers := 3
 	
-	// Start workers
-	for w := 1; w <= numWorkers; w++ {
-		processWg.Add(1)
-		go processingWorker(w, fetchedData, processedData, stats, &processWg)
+	// Workers take the most urgent item first, not simply the oldest
+	// (-dispatch priority), or take turns between sources (-dispatch fair)
+	dispatched := make(chan *APIResponse)
+	watch.Channel("dispatched", dispatched)
+	switch *dispatchMode {
+	case "fair":
+		go dispatchFairly(fetchedData, dispatched, NewDRRScheduler(1, integratedWeights,
+			func(r *APIResponse) string { return r.Source }, nil))
+	default:
+		go dispatchByPriority(fetchedData, dispatched,
+			func(r *APIResponse) Priority { return r.Priority }, priorityAging)
 	}
 	
+	// Start workers: between -min-workers and -max-workers of them, as
+	// many as the backlog needs (AUTOSCALING)
+	pool := NewAutoscalingPool(PoolConfig{
+		MinWorkers:       *minWorkers,
+		MaxWorkers:       *maxWorkers,
+		TargetQueueDelay: 200 * time.Millisecond,
+		ScaleDownAfter:   300 * time.Millisecond,
+		QueueDepth:       func() int { return int(waiting.Load()) },
+		OnScale:          func(e ScaleEvent) { fmt.Printf("   %v\n", e) },
+	}, dispatched, func(id int, job *APIResponse) {
+		waiting.Add(-1)
+		processItem(id, job, processedData, withFaults(processor, fmt.Sprintf("Worker-%d", id)), dlq, stats)
+	})
+	pool.Start()
+	
 	// Close results when all workers done
 	go func() {
-		processWg.Wait()
+		pool.Wait()
 		close(processedData)
 	}()
 	
@@ -399,49 +893,380 @@ func demonstrateIntegrated() {
 	go outputPipeline(processedData, done)
 	
 	// Wait for pipeline to complete
-	<-done
+	return <-done
+}
+
+// Data sources of the integrated demo, from three groups (APIs, Database,
+// Cache) that each get a bulkhead of their own under -bulkhead
+var integratedSources = []string{"API-1", "API-2", "API-3", "Database-1", "Database-2", "Cache"}
+
+// Some sources matter more than others (PRIORITY pattern)
+var integratedPriorities = map[string]Priority{"API-1": PriorityHigh, "Cache": PriorityLow}
+
+// ... or a bigger share of the workers under -dispatch fair (FAIR SCHEDULING)
+var integratedWeights = map[string]int{"API-1": 2}
+
+// INTEGRATED DEMONSTRATION
+func demonstrateIntegrated() {
+	fmt.Println("\n=== 🎯 INTEGRATED DEMO: All Patterns Combined ===")
+	fmt.Println("Scenario: Fetch data from APIs, process with workers, output via pipeline")
+	fmt.Println()
+	
+	// Initialize statistics (MUTEX pattern)
+	stats := NewStats()
+	defer stats.Close()
+	
+	// What the workers do with each item (-processor)
+	processor, err := lookupProcessor(*processorName)
+	if err != nil {
+		fmt.Printf("   ⚠️  %v\n", err)
+		return
+	}
+	
+	// Items that fail for good are parked here for inspection and replay
+	dlq, err := OpenDeadLetterQueue(*deadLetterPath)
+	if err != nil {
+		fmt.Printf("   ⚠️  Could not open dead-letter queue: %v\n", err)
+		return
+	}
+	
+	// Fetched data is reused across cycles until it gets old (RESULT CACHE)
+	cache := NewTTLCache[*APIResponse](*cacheTTL, *cacheTTL, *cacheSize)
+	defer cache.Wait()
+	
+	// Each source group gets its own fetch slots (-bulkhead)
+	var bulkheads *Bulkheads
+	if *bulkheadSize > 0 {
+		bulkheads = NewBulkheads(*bulkheadSize, bulkheadWait)
+	}
+	
+	// Service mode (-cycles) runs the pipeline again and again; Ctrl+C
+	// finishes the current cycle and stops
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	
+	// Worker processes do the processing instead (-remote); the pool's
+	// goroutines just carry items to them (REMOTE WORKERS)
+	var coordinator *Coordinator
+	if *remoteAddr != "" {
+		if coordinator, err = ListenCoordinator(*remoteAddr); err != nil {
+			fmt.Printf("   ⚠️  %v\n", err)
+			return
+		}
+		defer coordinator.Close()
+		fmt.Printf("🔌 Waiting for a worker: go run . worker -connect %s\n", coordinator.Addr())
+		if coordinator.WaitForWorkers(interrupted, 1) != nil {
+			fmt.Println("\n🛑 Interrupted")
+			return
+		}
+		processor = coordinator
+	}
+	
+	// Every item must come out exactly once, checked after each cycle (LEDGER)
+	accounts = ledger.New()
+	var unbalanced error
+	for cycle := 1; *cycles == 0 || cycle <= *cycles; cycle++ {
+		if cycle > 1 {
+			select {
+			case <-time.After(*cycleInterval):
+			case <-interrupted.Done():
+			}
+		}
+		if interrupted.Err() != nil {
+			fmt.Println("\n🛑 Interrupted")
+			break
+		}
+		if *cycles != 1 {
+			fmt.Printf("\n🔁 Cycle %d\n", cycle)
+		}
+		live.Emit(livefeed.Event{Kind: livefeed.Cycle, Detail: strconv.Itoa(cycle)})
+		runIntegratedCycle(integratedSources, integratedPriorities, cache, bulkheads, processor, dlq, stats)
+		if unbalanced = accounts.Check(); unbalanced != nil {
+			fmt.Printf("\n❌ Cycle %d:\n%s", cycle, indent(unbalanced.Error(), "   "))
+			break
+		}
+	}
 	
 	// Print statistics (MUTEX protected)
 	stats.Print()
+	if n := cache.Evictions(); n > 0 {
+		fmt.Printf("   Cache evictions: %d (least recently used, -cache-size %d)\n", n, *cacheSize)
+	}
+	bulkheads.Print()
+	coordinator.Print()
+	if unbalanced == nil {
+		fmt.Printf("   🧾 Accounts balance: %s\n", accounts.Totals())
+	}
+	if report := injector.Report(); report != "" {
+		fmt.Println("   🧪 Faults injected:")
+		fmt.Print(indent(report, "      "))
+	}
+	
+	// Persist anything that failed so it can be replayed later
+	if n := dlq.Len(); n > 0 {
+		if err := dlq.Save(); err != nil {
+			fmt.Printf("   ⚠️  Could not save dead-letter queue: %v\n", err)
+		} else {
+			fmt.Printf("\n☠️  %d item(s) in dead-letter queue %s\n", n, *deadLetterPath)
+			fmt.Println("   Inspect with: go run . dlq list")
+			fmt.Println("   Replay with:  go run . dlq replay")
+		}
+	}
+	
+	if unbalanced != nil {
+		fmt.Println("\n❌ Integrated demo failed: items were lost or duplicated (see above)")
+		os.Exit(1)
+	}
 	
 	fmt.Println("\n✅ Integrated demo completed!")
 	fmt.Println("\nPatterns used:")
 	fmt.Println("   ✓ Async Fetching: Concurrent API calls")
 	fmt.Println("   ✓ Select: Timeout handling")
 	fmt.Println("   ✓ Worker Pool: Limited concurrent processors")
+	fmt.Println("   ✓ Priority: Urgent items jump the queue")
 	fmt.Println("   ✓ Pipeline: Data flows through stages")
+	fmt.Println("   ✓ Cache: Fetched data reused while fresh, refreshed in the background")
 	fmt.Println("   ✓ Mutex: Thread-safe statistics")
 	fmt.Println("   ✓ WaitGroups: Synchronization at each stage")
 }
 
+// Demos that can be selected with -demo
+var demos = []struct {
+	name        string
+	description string
+	run         func()
+}{
+	{"integrated", "All patterns combined: fetch → worker pool → output", demonstrateIntegrated},
+	{"workerpool", "Worker pool pattern", demonstrateWorkerPool},
+	{"async", "Async data fetching", demonstrateAsyncFetching},
+	{"mutex", "Thread-safe counter using a mutex", demonstrateMutex},
+	{"pipeline", "Channel pipeline", demonstratePipeline},
+	{"select", "Select statement for channel multiplexing", demonstrateSelect},
+	{"priority", "Priority dispatcher with aging vs FIFO", demonstratePriority},
+	{"autoscale", "Worker pool that grows and shrinks with queue depth", demonstrateAutoscaling},
+	{"fair", "Deficit round robin across sources vs FIFO", demonstrateFairScheduling},
+	{"processors", "Real CPU/I-O work handlers: parallel speedup", demonstrateProcessors},
+	{"counters", "Mutex vs atomic vs sharded vs channel-owner counters", demonstrateCounters},
+	{"cache", "Shared cache: RWMutex, Once, Cond, and RWMutex vs sync.Map", demonstrateCache},
+	{"singleflight", "Coalescing concurrent fetches of the same source", demonstrateSingleflight},
+	{"hedge", "Hedged requests: cutting tail latency with a second attempt", demonstrateHedging},
+	{"bulkhead", "Bulkheads: a hanging source group cannot starve the others", demonstrateBulkheads},
+	{"faults", "Fault injection: seeded errors, spikes, hangs, panics, partial data", demonstrateFaults},
+	{"remote", "Worker pool spread over processes: length-prefixed JSON over TCP", demonstrateRemoteWorkers},
+}
+
+// listDemos prints every demo that can be passed to -demo
+func listDemos() {
+	fmt.Println("Available demos (go run . -demo <name>):")
+	for _, d := range demos {
+		fmt.Printf("   %-12s %s\n", d.name, d.description)
+	}
+}
+
+// watch notices stalls (-watchdog). Pipeline stages report progress to it;
+// it is nil, and does nothing, unless the flag is set.
+var watch *watchdog.Watchdog
+
+// injector makes sources and workers misbehave as -faults says; nil (and a
+// no-op) otherwise
+var injector *faults.Injector
+
+// accounts follows every item of the integrated pipeline from fetch to
+// output (see ledger/); nil, and a no-op, outside it
+var accounts *ledger.Ledger
+
+// live streams the integrated pipeline's events to the page served by -live;
+// nil (and a no-op) otherwise
+var live *livefeed.Feed
+
+// recorder collects the spans drawn by -timeline; nil (and a no-op) otherwise
+var recorder *timeline.Recorder
+
+// Command-line options
+var (
+	demoName       = flag.String("demo", "integrated", "demo to run (go run . list shows all)")
+	deadLetterPath = flag.String("dlq", "deadletters.jsonl", "file where dead-lettered items are stored")
+	processorName  = flag.String("processor", "simulated", "what the integrated demo's workers do: "+processorNames())
+	minWorkers     = flag.Int("min-workers", 1, "fewest workers the integrated demo's pool shrinks to")
+	maxWorkers     = flag.Int("max-workers", 3, "most workers the integrated demo's pool grows to when items queue up")
+	dispatchMode   = flag.String("dispatch", "priority", "how the integrated demo's workers get items: priority (most urgent first) or fair (deficit round robin across sources)")
+	failRate       = flag.Float64("fail-rate", processingFailureRate, "chance (0-1) that a processing attempt fails")
+	statsCounter   = flag.String("stats", "mutex", "counter the statistics use for their totals: "+counterNames())
+	stallWindow    = flag.Duration("watchdog", 0, "exit with a diagnostic if the demo makes no progress for this long (e.g. 5s; 0 = off)")
+	checkLeaks     = flag.Bool("check-leaks", false, "report goroutines the demo leaves running, and exit with status 1 if there are any")
+	timelinePath   = flag.String("timeline", "", "write an HTML Gantt chart of what each worker did and when to this file")
+	faultSpec      = flag.String("faults", "", "make sources and workers misbehave, e.g. 'API-3=error:0.3,hang:0.1;Worker-*=slow:1:200ms' (see faults/)")
+	liveAddr       = flag.String("live", "", "serve a page that animates the pipeline as it runs on this address, e.g. localhost:8081")
+	remoteAddr     = flag.String("remote", "", "have worker processes (go run . worker) do the integrated demo's processing; listen for them on this address, e.g. localhost:9090")
+	seed           = flag.Int64("seed", 0, "seed for the random latencies and injected faults, to repeat a run (0 = pick one)")
+	
+	// Service mode: the integrated demo runs its pipeline in cycles
+	cycles        = flag.Int("cycles", 1, "how many times the integrated demo runs its pipeline (0 = until interrupted)")
+	cycleInterval = flag.Duration("interval", time.Second, "pause between pipeline cycles")
+	cacheTTL      = flag.Duration("cache-ttl", 2*time.Second, "how long fetched data is reused; for as long again it is served stale while being refreshed")
+	cacheSize     = flag.Int("cache-size", 100, "most sources the fetch cache holds before evicting the least recently used")
+	bulkheadSize  = flag.Int("bulkhead", 0, "fetches that may run at once per source group (APIs, Database, ...); 0 = no limit")
+	hedgeDelay    = flag.Duration("hedge", 0, "start a second fetch attempt when the first has not answered after this long, e.g. 600ms (0 = off)")
+	
+	// Deadlines
+	pipelineDeadline = flag.Duration("deadline", 0, "time each pipeline cycle has to fetch and process everything (0 = none)")
+	itemBudget       = flag.Duration("item-budget", 0, "time each item has from the start of its fetch until it is processed (0 = none)")
+	
+	// Profiling (see profiling.go)
+	tracePath        = flag.String("trace", "", "write an execution trace to this file (go tool trace)")
+	cpuProfilePath   = flag.String("cpuprofile", "", "write a CPU profile to this file (go tool pprof)")
+	memProfilePath   = flag.String("memprofile", "", "write a heap profile, taken after the demo, to this file")
+	blockProfilePath = flag.String("blockprofile", "", "write a profile of where goroutines blocked to this file")
+	mutexProfilePath = flag.String("mutexprofile", "", "write a profile of mutex contention to this file")
+)
+
 func main() {
+	flag.Parse()
+	processingFailureRate = *failRate
+	newCounter, err := lookupCounter(*statsCounter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	newStatsCounter = newCounter
+	if *dispatchMode != "priority" && *dispatchMode != "fair" {
+		fmt.Fprintf(os.Stderr, "unknown dispatcher %q (want priority or fair)\n", *dispatchMode)
+		os.Exit(2)
+	}
+	
+	// Seed random number generator
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	rand.Seed(*seed)
+	if *faultSpec != "" {
+		if injector, err = faults.Parse(*faultSpec, *seed); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	
+	// Subcommands
+	switch flag.Arg(0) {
+	case "":
+	case "list":
+		listDemos()
+		return
+	case "dlq":
+		if err := runDeadLetterCommand(*deadLetterPath, flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "dlq:", err)
+			os.Exit(1)
+		}
+		return
+	case "serve":
+		if err := runServer(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "serve:", err)
+			os.Exit(1)
+		}
+		return
+	case "soak":
+		if err := runSoak(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "soak:", err)
+			os.Exit(1)
+		}
+		return
+	case "worker":
+		if err := runRemoteWorker(flag.Args()[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "worker:", err)
+			os.Exit(1)
+		}
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+	
+	var run func()
+	for _, d := range demos {
+		if d.name == *demoName {
+			run = d.run
+		}
+	}
+	if run == nil {
+		fmt.Fprintf(os.Stderr, "unknown demo %q\n", *demoName)
+		listDemos()
+		os.Exit(2)
+	}
+	
 	fmt.Println("===========================================")
 	fmt.Println("  Go Concurrency & Async Programming Demo")
 	fmt.Println("===========================================")
+	if injector != nil {
+		fmt.Printf("🧪 Injecting faults: %s (-seed %d repeats them)\n", *faultSpec, *seed)
+	}
 	
-	// Seed random number generator
-	rand.Seed(time.Now().UnixNano())
+	// Waiting for a browser is not a stall, so the watchdog starts after it;
+	// Ctrl+C ends the wait
+	waiting, stopWaiting := signal.NotifyContext(context.Background(), os.Interrupt)
+	stopLiveView, err := startLiveView(waiting, *liveAddr)
+	stopWaiting()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "live:", err)
+		os.Exit(1)
+	}
 	
-	// Option 1: Individual demonstrations
-	fmt.Println("\n📚 Choose demo mode:")
-	fmt.Println("   Running INTEGRATED demo (combines all patterns)")
-	fmt.Println()
+	// Output counts as progress too, so demos without stages are covered
+	if *stallWindow > 0 {
+		watch = watchdog.New(*stallWindow)
+	}
+	restoreStdout := watch.WatchStdout()
+	watch.Start()
+	
+	var snapshot leakcheck.Snapshot
+	if *checkLeaks {
+		snapshot = leakcheck.Take()
+	}
+	
+	if *timelinePath != "" {
+		recorder = timeline.New(*demoName + " demo")
+	}
+	
+	stopProfiling, err := startProfiling(*demoName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "profiling:", err)
+		os.Exit(1)
+	}
+	
+	run()
+	
+	if err := stopProfiling(); err != nil {
+		fmt.Fprintln(os.Stderr, "profiling:", err)
+		os.Exit(1)
+	}
+	stopLiveView()
+	
+	watch.Stop()
+	restoreStdout()
 	
-	demonstrateIntegrated()
-	
-	fmt.Println("\n================================================")
-	fmt.Println("💡 Want to see individual patterns?")
-	fmt.Println("   Uncomment the sections below in main()")
-	fmt.Println("================================================")
-	
-	// Uncomment to see individual demos:
-	/*
-	demonstrateWorkerPool()
-	demonstrateAsyncFetching()
-	demonstrateMutex()
-	demonstratePipeline()
-	demonstrateSelect()
-	*/
+	if recorder != nil {
+		if err := recorder.Save(*timelinePath); err != nil {
+			fmt.Fprintln(os.Stderr, "timeline:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n📊 Timeline written to %s (open it in a browser)\n", *timelinePath)
+	}
+	
+	if *checkLeaks {
+		if leaks := snapshot.Leaked(); len(leaks) > 0 {
+			fmt.Print("\n" + leakcheck.Report(leaks))
+			os.Exit(1)
+		}
+		fmt.Println("\n✅ No goroutines leaked")
+	}
+	
+	if *demoName == "integrated" {
+		fmt.Println("\n================================================")
+		fmt.Println("💡 Want to see individual patterns?")
+		fmt.Println("   go run . list              # show all demos")
+		fmt.Println("   go run . -demo workerpool  # run one of them")
+		fmt.Println("================================================")
+	}
 	
 	fmt.Println("\n===========================================")
 	fmt.Println("  All demos completed successfully!")
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// PRIORITY QUEUES: High-priority work jumps ahead, aging prevents starvation
+// ============================================================================
+
+// Priority of a job; the zero value is PriorityNormal
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// Job is a unit of work for the priority worker pool demo
+type Job struct {
+	ID       int
+	Priority Priority
+	Work     time.Duration
+}
+
+// queued wraps a value with what the heap needs to order it
+type queued[T any] struct {
+	value    T
+	priority Priority
+	enqueued time.Time
+	seq      uint64
+}
+
+// rank orders items by "virtual arrival time": every priority class is worth
+// one aging interval of waiting. A low item that has waited longer than the
+// aging interval therefore outranks a normal item that just arrived, which is
+// what prevents starvation. Because every waiting item ages at the same rate,
+// the ranking never changes while items sit in the heap.
+func (q queued[T]) rank(aging time.Duration) time.Time {
+	return q.enqueued.Add(-time.Duration(q.priority) * aging)
+}
+
+// priorityHeap implements heap.Interface
+type priorityHeap[T any] struct {
+	items []queued[T]
+	aging time.Duration
+}
+
+func (h *priorityHeap[T]) Len() int { return len(h.items) }
+
+func (h *priorityHeap[T]) Less(i, j int) bool {
+	ri, rj := h.items[i].rank(h.aging), h.items[j].rank(h.aging)
+	if ri.Equal(rj) {
+		return h.items[i].seq < h.items[j].seq
+	}
+	return ri.Before(rj)
+}
+
+func (h *priorityHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *priorityHeap[T]) Push(x any) { h.items = append(h.items, x.(queued[T])) }
+
+func (h *priorityHeap[T]) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// PriorityQueue is a concurrency-safe priority queue with aging
+type PriorityQueue[T any] struct {
+	mu   sync.Mutex
+	heap priorityHeap[T]
+	seq  uint64
+}
+
+// NewPriorityQueue creates a queue where waiting for aging raises an item by one class
+func NewPriorityQueue[T any](aging time.Duration) *PriorityQueue[T] {
+	return &PriorityQueue[T]{heap: priorityHeap[T]{aging: aging}}
+}
+
+// Push adds a value with the given priority
+func (q *PriorityQueue[T]) Push(value T, p Priority) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	heap.Push(&q.heap, queued[T]{value: value, priority: p, enqueued: time.Now(), seq: q.seq})
+}
+
+// Peek returns the value that Pop would return without removing it
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.heap.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.heap.items[0].value, true
+}
+
+// Pop removes and returns the most urgent value
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.heap.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(&q.heap).(queued[T]).value, true
+}
+
+// Len returns the number of queued values
+func (q *PriorityQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.heap.items)
+}
+
+// dispatchByPriority sits between a FIFO input channel and the workers.
+// Everything that arrives is queued by priority; out should be unbuffered so
+// work stays in the queue (where it can be overtaken) until a worker is free.
+// out is closed once in is closed and the queue has drained.
+func dispatchByPriority[T any](in <-chan T, out chan<- T, priorityOf func(T) Priority, aging time.Duration) {
+	queue := NewPriorityQueue[T](aging)
+
+	for {
+		// Nothing queued: block until something arrives
+		if queue.Len() == 0 {
+			item, ok := <-in
+			if !ok {
+				close(out)
+				return
+			}
+			queue.Push(item, priorityOf(item))
+			continue
+		}
+
+		// Hand the most urgent item to a free worker, or accept new arrivals
+		next, _ := queue.Peek()
+		select {
+		case item, ok := <-in:
+			if !ok {
+				// Input finished: drain what is left in priority order
+				for queue.Len() > 0 {
+					item, _ := queue.Pop()
+					out <- item
+				}
+				close(out)
+				return
+			}
+			queue.Push(item, priorityOf(item))
+		case out <- next:
+			queue.Pop()
+		}
+	}
+}
+
+// jobWait is how long a job waited in the queue before a worker picked it up
+type jobWait struct {
+	priority Priority
+	wait     time.Duration
+}
+
+// runPriorityPool pushes jobs through numWorkers workers and reports queue waits.
+// With usePriority false the jobs are served FIFO straight from the channel.
+func runPriorityPool(jobs []Job, numWorkers int, usePriority bool, aging time.Duration) []jobWait {
+	type timedJob struct {
+		job      Job
+		enqueued time.Time
+	}
+
+	arrivals := make(chan timedJob, len(jobs))
+	work := (<-chan timedJob)(arrivals)
+	if usePriority {
+		dispatched := make(chan timedJob)
+		go dispatchByPriority(arrivals, dispatched, func(tj timedJob) Priority { return tj.job.Priority }, aging)
+		work = dispatched
+	}
+
+	var mu sync.Mutex
+	var waits []jobWait
+	var wg sync.WaitGroup
+	for w := 1; w <= numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tj := range work {
+				mu.Lock()
+				waits = append(waits, jobWait{tj.job.Priority, time.Since(tj.enqueued)})
+				mu.Unlock()
+				time.Sleep(tj.job.Work)
+			}
+		}()
+	}
+
+	// Jobs arrive in bursts, faster than the workers can keep up
+	for i, job := range jobs {
+		arrivals <- timedJob{job: job, enqueued: time.Now()}
+		if i%10 == 9 {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	close(arrivals)
+
+	wg.Wait()
+	return waits
+}
+
+// percentile returns the nearest-rank percentile (0-1) of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// printWaits prints count, average, p95 and max wait per priority class
+func printWaits(label string, waits []jobWait) {
+	byClass := make(map[Priority][]time.Duration)
+	for _, w := range waits {
+		byClass[w.priority] = append(byClass[w.priority], w.wait)
+	}
+
+	fmt.Printf("\n%s\n", label)
+	fmt.Printf("   %-7s %5s %10s %10s %10s\n", "class", "jobs", "avg", "p95", "max")
+	for _, p := range []Priority{PriorityHigh, PriorityNormal, PriorityLow} {
+		ds := byClass[p]
+		if len(ds) == 0 {
+			continue
+		}
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		var total time.Duration
+		for _, d := range ds {
+			total += d
+		}
+		fmt.Printf("   %-7s %5d %10v %10v %10v\n", p, len(ds),
+			(total / time.Duration(len(ds))).Round(time.Millisecond),
+			percentile(ds, 0.95).Round(time.Millisecond), ds[len(ds)-1].Round(time.Millisecond))
+	}
+}
+
+// demonstratePriority compares FIFO with the priority dispatcher
+func demonstratePriority() {
+	fmt.Println("\n=== Priority Queue Demo ===")
+
+	numWorkers := 2
+	numJobs := 60
+	aging := 500 * time.Millisecond
+
+	jobs := make([]Job, numJobs)
+	for i := range jobs {
+		p := PriorityLow
+		switch r := rand.Intn(10); {
+		case r < 2:
+			p = PriorityHigh
+		case r < 5:
+			p = PriorityNormal
+		}
+		jobs[i] = Job{ID: i + 1, Priority: p, Work: time.Duration(20+rand.Intn(20)) * time.Millisecond}
+	}
+
+	fmt.Printf("%d jobs, %d workers, aging: +1 class per %v waited\n", numJobs, numWorkers, aging)
+
+	printWaits("📥 FIFO (single channel) - queue wait per class:", runPriorityPool(jobs, numWorkers, false, aging))
+	printWaits("🚦 Priority dispatcher - queue wait per class:", runPriorityPool(jobs, numWorkers, true, aging))
+
+	fmt.Println("\nHigh-priority jobs overtake queued ones; aging caps how long low ones wait.")
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ============================================================================
+// PROCESSORS: Real CPU-bound and I/O-bound work for the worker pool
+// ============================================================================
+
+// A Sampler is a Processor that only accepts input of a particular shape.
+// The simulated sources return Sample(source) for it, instead of the
+// "data-from-<source>" every other processor takes.
+type Sampler interface {
+	Sample(source string) string
+}
+
+// sampleData is what a simulated source returns when processor is to
+// process it
+func sampleData(processor Processor, source string) string {
+	if s, ok := processor.(Sampler); ok {
+		return s.Sample(source)
+	}
+	return "data-from-" + source
+}
+
+// HashProcessor is CPU-bound: it hashes the data with SHA-256, Rounds times over
+type HashProcessor struct {
+	Rounds int
+}
+
+func (p HashProcessor) Process(job *APIResponse) (string, error) {
+	sum := sha256.Sum256([]byte(job.Data))
+	for i := 1; i < p.Rounds; i++ {
+		sum = sha256.Sum256(sum[:])
+	}
+	return "sha256:" + hex.EncodeToString(sum[:8]), nil
+}
+
+// GzipProcessor is CPU-bound: it compresses the data and reports the ratio
+type GzipProcessor struct {
+	Level int
+}
+
+func (p GzipProcessor) Process(job *APIResponse) (string, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, p.Level)
+	if err != nil {
+		return "", err
+	}
+	if _, err := zw.Write([]byte(job.Data)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	if len(job.Data) == 0 { // no ratio to report
+		return fmt.Sprintf("gzip: 0 → %d bytes", buf.Len()), nil
+	}
+	return fmt.Sprintf("gzip: %d → %d bytes (%.0f%%)",
+		len(job.Data), buf.Len(), 100*float64(buf.Len())/float64(len(job.Data))), nil
+}
+
+// JSONTransformProcessor decodes a JSON document, normalises it (keys lower-cased,
+// strings trimmed) and re-encodes it with sorted keys
+type JSONTransformProcessor struct{}
+
+func (JSONTransformProcessor) Process(job *APIResponse) (string, error) {
+	var doc any
+	if err := json.Unmarshal([]byte(job.Data), &doc); err != nil {
+		return "", permanent(fmt.Errorf("invalid JSON from %s: %w", job.Source, err))
+	}
+	out, err := json.Marshal(normaliseJSON(doc))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Sample is a small document with keys and strings to normalise
+func (JSONTransformProcessor) Sample(source string) string {
+	return fmt.Sprintf(`{"Source":"  %s  ","Status":" OK ","Tags":["  Fetched "," Raw  "],"Size":%d}`,
+		source, len(source))
+}
+
+// normaliseJSON walks a decoded JSON value
+func normaliseJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			m[strings.ToLower(key)] = normaliseJSON(val)
+		}
+		return m
+	case []any:
+		for i := range v {
+			v[i] = normaliseJSON(v[i])
+		}
+		return v
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return v
+	}
+}
+
+// WordCountProcessor is I/O-bound: the data is the path of a file in Files,
+// the result is the file's word count and its most frequent words
+type WordCountProcessor struct {
+	Top   int
+	Files fs.FS // nil if there are none
+}
+
+func (p WordCountProcessor) Process(job *APIResponse) (string, error) {
+	if p.Files == nil {
+		return "", permanent(errors.New("no files to count words in: the source tree is not available"))
+	}
+	content, err := fs.ReadFile(p.Files, job.Data)
+	if err != nil {
+		return "", permanent(err)
+	}
+
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(string(content)), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		counts[w]++
+	}
+
+	type wordCount struct {
+		word  string
+		count int
+	}
+	ranked := make([]wordCount, 0, len(counts))
+	for w, c := range counts {
+		ranked = append(ranked, wordCount{w, c})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count == ranked[j].count {
+			return ranked[i].word < ranked[j].word
+		}
+		return ranked[i].count > ranked[j].count
+	})
+
+	top := make([]string, 0, p.Top)
+	for i := 0; i < p.Top && i < len(ranked); i++ {
+		top = append(top, fmt.Sprintf("%s(%d)", ranked[i].word, ranked[i].count))
+	}
+	return fmt.Sprintf("%d words, top: %s", len(words), strings.Join(top, " ")), nil
+}
+
+// Sample picks one of the text files for source, the same one every time
+func (p WordCountProcessor) Sample(source string) string {
+	files := textFiles(p.Files)
+	if len(files) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(source))
+	return files[h.Sum32()%uint32(len(files))]
+}
+
+// sourceTree is the directory this program was built from: text for the
+// wordcount processor that does not depend on where the demo is started
+var sourceTree = openSourceTree()
+
+// openSourceTree finds the source tree by way of this file's path. It
+// returns nil if the tree is not there, e.g. for a binary copied elsewhere.
+func openSourceTree() fs.FS {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil
+	}
+	dir := filepath.Dir(file)
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return nil
+	}
+	return os.DirFS(dir)
+}
+
+// textFiles lists the .go, .md and .txt files in fsys, leaving out hidden
+// directories
+func textFiles(fsys fs.FS) []string {
+	if fsys == nil {
+		return nil
+	}
+	var files []string
+	fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != "." {
+			return fs.SkipDir
+		}
+		switch filepath.Ext(path) {
+		case ".go", ".md", ".txt":
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// Processors selectable with -processor
+var processorsByName = map[string]Processor{
+	"simulated": ProcessFunc(simulateProcessing),
+	"hash":      HashProcessor{Rounds: 20000},
+	"gzip":      GzipProcessor{Level: gzip.BestCompression},
+	"json":      JSONTransformProcessor{},
+	"wordcount": WordCountProcessor{Top: 3, Files: sourceTree},
+}
+
+// processorNames lists the processor names for help text
+func processorNames() string {
+	names := make([]string, 0, len(processorsByName))
+	for name := range processorsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// demoProcessor is the processor -processor names. It decides what the
+// simulated sources return (see Sampler).
+func demoProcessor() Processor {
+	if p, err := lookupProcessor(*processorName); err == nil {
+		return p
+	}
+	return processorsByName["simulated"]
+}
+
+// lookupProcessor finds a processor by name
+func lookupProcessor(name string) (Processor, error) {
+	p, ok := processorsByName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown processor %q (want one of: %s)", name, processorNames())
+	}
+	return p, nil
+}
+
+// runProcessorPool processes items with numWorkers processingWorkers and
+// returns how long it took and how many items failed
+func runProcessorPool(processor Processor, items []*APIResponse, numWorkers int) (time.Duration, int) {
+	jobs := make(chan *APIResponse, len(items))
+	for _, item := range items {
+		jobs <- item
+	}
+	close(jobs)
+
+	results := make(chan ProcessedData, len(items))
+	dlq := NewDeadLetterQueue("")
+	stats := NewStats()
+	defer stats.Close()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 1; w <= numWorkers; w++ {
+		wg.Add(1)
+		go processingWorker(w, jobs, results, processor, dlq, stats, &wg)
+	}
+	wg.Wait()
+	return time.Since(start), dlq.Len()
+}
+
+// processorWorkload is a processor plus enough input to keep it busy
+type processorWorkload struct {
+	name      string
+	processor Processor
+	items     []*APIResponse
+}
+
+// processorWorkloads builds a workload of roughly similar size for each processor
+func processorWorkloads() []processorWorkload {
+	words := strings.Fields("goroutine channel select mutex worker pipeline context deadline buffer")
+	text := func(n int) string {
+		var sb strings.Builder
+		for sb.Len() < n {
+			sb.WriteString(words[rand.Intn(len(words))])
+			sb.WriteByte(' ')
+		}
+		return sb.String()
+	}
+	items := func(n int, data func(i int) (source, data string)) []*APIResponse {
+		out := make([]*APIResponse, n)
+		for i := range out {
+			source, d := data(i + 1)
+			out[i] = &APIResponse{Source: source, Data: d}
+		}
+		return out
+	}
+
+	// Every text file in the project, read a few times over
+	files := textFiles(sourceTree)
+
+	return []processorWorkload{
+		{"hash", HashProcessor{Rounds: 50000}, items(32, func(i int) (string, string) {
+			return fmt.Sprintf("block-%d", i), text(4 << 10)
+		})},
+		{"gzip", GzipProcessor{Level: gzip.BestCompression}, items(16, func(i int) (string, string) {
+			return fmt.Sprintf("log-%d", i), text(64 << 10)
+		})},
+		{"json", JSONTransformProcessor{}, items(1000, func(i int) (string, string) {
+			doc := make(map[string]any)
+			for f := 0; f < 200; f++ {
+				doc[fmt.Sprintf("Field_%d", f)] = "  " + text(32) + "  "
+			}
+			data, _ := json.Marshal(doc)
+			return fmt.Sprintf("doc-%d", i), string(data)
+		})},
+		{"wordcount", WordCountProcessor{Top: 3, Files: sourceTree}, items(32*len(files), func(i int) (string, string) {
+			f := files[(i-1)%len(files)]
+			return f, f
+		})},
+	}
+}
+
+// demonstrateProcessors runs each real processor with one worker and with two
+// workers per CPU, and reports the parallel speedup
+func demonstrateProcessors() {
+	fmt.Println("\n=== Real Work Processors Demo ===")
+
+	numCPU := runtime.GOMAXPROCS(0)
+	fmt.Printf("GOMAXPROCS: %d (CPU-bound work can only speed up this much)\n", numCPU)
+	if numCPU == 1 {
+		fmt.Println("Only one CPU available: expect little speedup for CPU-bound processors.")
+	}
+
+	manyWorkers := numCPU * 2
+
+	fmt.Printf("\n   %-10s %6s %12s %12s %8s\n", "processor", "items", "1 worker", fmt.Sprintf("%d workers", manyWorkers), "speedup")
+	for _, w := range processorWorkloads() {
+		if len(w.items) == 0 {
+			continue
+		}
+		serial, failed := runProcessorPool(w.processor, w.items, 1)
+		parallel, _ := runProcessorPool(w.processor, w.items, manyWorkers)
+
+		fmt.Printf("   %-10s %6d %12v %12v %7.2fx\n", w.name, len(w.items),
+			serial.Round(time.Millisecond), parallel.Round(time.Millisecond),
+			serial.Seconds()/parallel.Seconds())
+		if failed > 0 {
+			fmt.Printf("   ⚠️  %d %s item(s) failed\n", failed, w.name)
+		}
+
+		if result, err := w.processor.Process(w.items[0]); err == nil && len(result) < 100 {
+			fmt.Printf("      e.g. %s → %s\n", w.items[0].Source, result)
+		}
+	}
+}
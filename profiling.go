@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// ============================================================================
+// PROFILING: Execution traces and pprof profiles of any demo
+// ============================================================================
+
+// traceCtx carries the current demo's trace task, so the regions that stages
+// open show up under it in `go tool trace`. Regions cost next to nothing
+// while no trace is being recorded. It is set once, before the demo starts
+// any goroutine, and never changed: goroutines still running when tracing
+// stops (background cache refreshes, losing hedge attempts) may still read it.
+var traceCtx = context.Background()
+
+// traceRegion marks one unit of a stage's work in the execution trace
+func traceRegion(stage string) *trace.Region {
+	return trace.StartRegion(traceCtx, stage)
+}
+
+// profileFile is an artifact written while (or after) a demo runs
+type profileFile struct {
+	flag string // the command-line option that asked for it
+	path string
+	tool string // how to open it
+}
+
+// startProfiling starts the execution trace and the profiles asked for on the
+// command line. The returned stop function ends them and writes every file.
+func startProfiling(demo string) (func() error, error) {
+	var (
+		written  []profileFile
+		stoppers []func() error
+	)
+	// finish stops everything started so far, newest first
+	finish := func() error {
+		var firstErr error
+		for i := len(stoppers) - 1; i >= 0; i-- {
+			if err := stoppers[i](); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+	fail := func(err error) (func() error, error) {
+		finish()
+		return nil, err
+	}
+
+	if *tracePath != "" {
+		f, err := os.Create(*tracePath)
+		if err != nil {
+			return fail(err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return fail(err)
+		}
+		ctx, task := trace.NewTask(context.Background(), "demo "+demo)
+		traceCtx = ctx
+		stoppers = append(stoppers, func() error {
+			task.End()
+			trace.Stop()
+			return f.Close()
+		})
+		written = append(written, profileFile{"trace", *tracePath, "go tool trace " + *tracePath})
+	}
+
+	if *cpuProfilePath != "" {
+		f, err := os.Create(*cpuProfilePath)
+		if err != nil {
+			return fail(err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fail(err)
+		}
+		stoppers = append(stoppers, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+		written = append(written, profileFile{"cpu profile", *cpuProfilePath, "go tool pprof " + *cpuProfilePath})
+	}
+
+	// Block and mutex events are only sampled while their rate is set, so
+	// record every one of them for the length of the demo
+	if *blockProfilePath != "" {
+		runtime.SetBlockProfileRate(1)
+		stoppers = append(stoppers, func() error {
+			runtime.SetBlockProfileRate(0)
+			return writeProfile("block", *blockProfilePath)
+		})
+		written = append(written, profileFile{"block profile", *blockProfilePath, "go tool pprof " + *blockProfilePath})
+	}
+	if *mutexProfilePath != "" {
+		runtime.SetMutexProfileFraction(1)
+		stoppers = append(stoppers, func() error {
+			runtime.SetMutexProfileFraction(0)
+			return writeProfile("mutex", *mutexProfilePath)
+		})
+		written = append(written, profileFile{"mutex profile", *mutexProfilePath, "go tool pprof " + *mutexProfilePath})
+	}
+
+	if *memProfilePath != "" {
+		stoppers = append(stoppers, func() error {
+			runtime.GC() // up-to-date statistics on what is still live
+			return writeProfile("heap", *memProfilePath)
+		})
+		written = append(written, profileFile{"memory profile", *memProfilePath, "go tool pprof " + *memProfilePath})
+	}
+
+	return func() error {
+		if err := finish(); err != nil {
+			return err
+		}
+		for _, p := range written {
+			fmt.Printf("📈 %s written to %s (%s)\n", p.flag, p.path, p.tool)
+		}
+		return nil
+	}, nil
+}
+
+// writeProfile writes one of the runtime's named profiles to a file
+func writeProfile(name, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
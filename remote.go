@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// REMOTE WORKERS: The worker pool spread over processes, length-prefixed JSON
+// ============================================================================
+
+// The protocol is a stream of frames over TCP: a 4-byte big-endian length,
+// then that many bytes of JSON (one remoteMessage). A worker says hello,
+// then answers each job the coordinator sends with one result.
+//
+//	worker → coordinator  {"type":"hello","name":"w1","processor":"hash"}
+//	coordinator → worker  {"type":"job","seq":1,"item":{"Source":"API-1",...},"budget":800000000}
+//	worker → coordinator  {"type":"result","seq":1,"result":"..."}  or  "error":"..."
+
+// maxFrame bounds a frame, so a corrupt length cannot make us allocate gigabytes
+const maxFrame = 1 << 20
+
+// How long a worker may take to answer one job before it counts as lost
+const remoteTimeout = 10 * time.Second
+
+// How often a job may be re-queued after losing its worker before it is
+// given up on
+const maxRequeues = 3
+
+// remoteMessage is every frame's JSON
+type remoteMessage struct {
+	Type      string        `json:"type"` // hello, job or result
+	Name      string        `json:"name,omitempty"`
+	Processor string        `json:"processor,omitempty"`
+	Seq       uint64        `json:"seq,omitempty"`
+	Item      *APIResponse  `json:"item,omitempty"`
+	Budget    time.Duration `json:"budget,omitempty"` // what is left of the item's time budget; 0 = none
+	Result    string        `json:"result,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Permanent bool          `json:"permanent,omitempty"` // the error will not go away on retry
+}
+
+// writeFrame sends m as one frame
+func writeFrame(w io.Writer, m remoteMessage) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 4+len(body))
+	binary.BigEndian.PutUint32(frame, uint32(len(body)))
+	copy(frame[4:], body)
+	_, err = w.Write(frame)
+	return err
+}
+
+// readFrame reads one frame into m
+func readFrame(r io.Reader, m *remoteMessage) error {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxFrame {
+		return fmt.Errorf("frame of %d bytes is over the %d byte limit", n, maxFrame)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+	return json.Unmarshal(body, m)
+}
+
+// Errors for jobs that never got an answer
+var (
+	errCoordinatorClosed = errors.New("coordinator closed")        // still waiting at shutdown
+	errWorkersLost       = errors.New("lost its worker too often") // re-queued maxRequeues times
+)
+
+// remoteTask is a job waiting for, or at, a remote worker
+type remoteTask struct {
+	job      *APIResponse
+	reply    chan remoteReply // buffered: the answer never waits for a reader
+	requeues int
+}
+
+type remoteReply struct {
+	result string
+	err    error
+}
+
+// remoteWorker is what the coordinator knows about one connected worker
+type remoteWorker struct {
+	name      string
+	processor string
+	conn      net.Conn
+	done      int
+}
+
+// Coordinator hands jobs to worker processes that connect to it. It is a
+// Processor: each Process call is one attempt, sent to whichever worker is
+// free. If a worker disconnects (or stops answering) with a job in flight,
+// the job goes back in the queue for another worker - that does not count
+// as an attempt. Processing errors come back as the attempt's error.
+type Coordinator struct {
+	listener net.Listener
+	tasks    chan *remoteTask // unbuffered: taken by idle worker connections
+	closed   chan struct{}
+	requeued atomic.Int64
+
+	mu      sync.Mutex
+	workers map[*remoteWorker]struct{}
+	history []*remoteWorker // every worker that ever connected, for Print
+	closing bool
+
+	wg sync.WaitGroup
+}
+
+// ListenCoordinator starts accepting workers on addr
+func ListenCoordinator(addr string) (*Coordinator, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Coordinator{
+		listener: listener,
+		tasks:    make(chan *remoteTask),
+		closed:   make(chan struct{}),
+		workers:  make(map[*remoteWorker]struct{}),
+	}
+	c.wg.Add(1)
+	go c.accept()
+	return c, nil
+}
+
+// Addr is the address workers connect to
+func (c *Coordinator) Addr() string { return c.listener.Addr().String() }
+
+// accept serves every worker that connects until Close
+func (c *Coordinator) accept() {
+	defer c.wg.Done()
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			return // closed
+		}
+		c.wg.Add(1)
+		go c.serve(conn)
+	}
+}
+
+// serve feeds one worker jobs, one at a time, until it goes away
+func (c *Coordinator) serve(conn net.Conn) {
+	defer c.wg.Done()
+	defer conn.Close()
+
+	var hello remoteMessage
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := readFrame(conn, &hello); err != nil || hello.Type != "hello" {
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	w := &remoteWorker{name: hello.Name, processor: hello.Processor, conn: conn}
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return
+	}
+	c.workers[w] = struct{}{}
+	c.history = append(c.history, w)
+	c.mu.Unlock()
+	fmt.Printf("   🔌 %s connected from %s (-processor %s)\n", w.name, conn.RemoteAddr(), w.processor)
+	defer func() {
+		c.mu.Lock()
+		delete(c.workers, w)
+		idle := len(c.workers) == 0 && !c.closing
+		c.mu.Unlock()
+		if idle {
+			fmt.Println("   🔌 No workers left: jobs wait until one connects")
+		}
+	}()
+
+	var seq uint64
+	for {
+		var task *remoteTask
+		select {
+		case task = <-c.tasks:
+		case <-c.closed:
+			return
+		}
+
+		// The worker gets what is left of the item's budget, and a little
+		// longer than that to answer
+		timeout, budget := remoteTimeout, time.Duration(0)
+		if !task.job.Deadline.IsZero() {
+			if budget = time.Until(task.job.Deadline); budget <= 0 {
+				task.reply <- remoteReply{err: context.DeadlineExceeded}
+				continue
+			}
+			timeout = min(timeout, budget+time.Second)
+		}
+
+		seq++
+		var reply remoteMessage
+		conn.SetDeadline(time.Now().Add(timeout))
+		err := writeFrame(conn, remoteMessage{Type: "job", Seq: seq, Item: task.job, Budget: budget})
+		if err == nil {
+			err = readFrame(conn, &reply)
+		}
+		if err == nil && (reply.Type != "result" || reply.Seq != seq) {
+			err = fmt.Errorf("unexpected %s #%d", reply.Type, reply.Seq)
+		}
+		if err != nil {
+			outcome := "back in the queue"
+			if !c.requeue(task) {
+				outcome = "giving up on it"
+			}
+			fmt.Printf("   🔌 %s lost with %s in flight (%v): %s\n", w.name, task.job.Source, err, outcome)
+			return
+		}
+
+		c.mu.Lock()
+		w.done++
+		c.mu.Unlock()
+		if reply.Error != "" {
+			err := errors.New(reply.Error)
+			if reply.Permanent {
+				err = permanent(err)
+			}
+			task.reply <- remoteReply{err: err}
+		} else {
+			task.reply <- remoteReply{result: reply.Result}
+		}
+		watch.Beat("remoteWorker")
+	}
+}
+
+// requeue offers a task whose worker was lost to the other workers, unless
+// its budget is spent or it was re-queued maxRequeues times already: then it
+// fails for good, so the item is dead-lettered rather than passed around.
+// It reports whether the task was re-queued.
+func (c *Coordinator) requeue(task *remoteTask) bool {
+	if !task.job.Deadline.IsZero() && time.Until(task.job.Deadline) <= 0 {
+		task.reply <- remoteReply{err: context.DeadlineExceeded}
+		return false
+	}
+	if task.requeues == maxRequeues {
+		task.reply <- remoteReply{err: permanent(fmt.Errorf("%s %w (%d times)", task.job.Source, errWorkersLost, maxRequeues+1))}
+		return false
+	}
+	task.requeues++
+	c.requeued.Add(1)
+	go func() {
+		select {
+		case c.tasks <- task:
+		case <-c.closed:
+			task.reply <- remoteReply{err: errCoordinatorClosed}
+		}
+	}()
+	return true
+}
+
+// Process sends job to the next free worker and waits for its answer
+func (c *Coordinator) Process(job *APIResponse) (string, error) {
+	ctx, cancel := job.Context()
+	defer cancel()
+
+	task := &remoteTask{job: job, reply: make(chan remoteReply, 1)}
+	select {
+	case c.tasks <- task:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-c.closed:
+		return "", errCoordinatorClosed
+	}
+	select {
+	case r := <-task.reply:
+		return r.result, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Workers returns how many workers are connected
+func (c *Coordinator) Workers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.workers)
+}
+
+// WaitForWorkers blocks until at least n workers are connected, or ctx ends
+func (c *Coordinator) WaitForWorkers(ctx context.Context, n int) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for c.Workers() < n {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close stops accepting workers and disconnects the connected ones, which
+// then exit
+func (c *Coordinator) Close() {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return
+	}
+	c.closing = true
+	close(c.closed)
+	c.listener.Close()
+	for w := range c.workers {
+		w.conn.Close()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+// Print reports how many jobs each worker did; a nil coordinator prints
+// nothing
+func (c *Coordinator) Print() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	workers := append([]*remoteWorker(nil), c.history...)
+	sort.Slice(workers, func(i, j int) bool { return workers[i].name < workers[j].name })
+	fmt.Printf("   🔌 Remote workers (%d job(s) re-queued after a worker was lost):\n", c.requeued.Load())
+	for _, w := range workers {
+		state := "connected"
+		if _, ok := c.workers[w]; !ok {
+			state = "gone"
+		}
+		fmt.Printf("      %-10s %3d job(s), %s\n", w.name+":", w.done, state)
+	}
+}
+
+// runRemoteWorker handles "worker": it connects to a coordinator and
+// processes the jobs it sends until the coordinator goes away or Ctrl+C
+func runRemoteWorker(args []string) error {
+	fs := flag.NewFlagSet("worker", flag.ContinueOnError)
+	addr := fs.String("connect", "localhost:9090", "address of the coordinator (the integrated demo's -remote)")
+	name := fs.String("name", fmt.Sprintf("worker-%d", os.Getpid()), "name the coordinator knows this worker by")
+	crashAfter := fs.Int("crash-after", 0, "exit without answering the nth job, to see it re-queued (0 = never)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	processor, err := lookupProcessor(*processorName)
+	if err != nil {
+		return err
+	}
+	processor = withFaults(processor, *name)
+
+	// The coordinator may not be up yet
+	var conn net.Conn
+	for wait := 100 * time.Millisecond; ; wait *= 2 {
+		if conn, err = net.Dial("tcp", *addr); err == nil {
+			break
+		}
+		if wait > 5*time.Second {
+			return err
+		}
+		time.Sleep(wait)
+	}
+	defer conn.Close()
+
+	// Ctrl+C drops the connection; the coordinator re-queues our job
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-interrupted.Done()
+		conn.Close()
+	}()
+
+	if err := writeFrame(conn, remoteMessage{Type: "hello", Name: *name, Processor: *processorName}); err != nil {
+		return err
+	}
+	fmt.Printf("👷 %s connected to %s\n", *name, *addr)
+
+	jobs := 0
+	for {
+		var m remoteMessage
+		if err := readFrame(conn, &m); err != nil {
+			if interrupted.Err() == nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			fmt.Printf("👷 %s: coordinator gone after %d job(s)\n", *name, jobs)
+			return nil
+		}
+		if m.Type != "job" || m.Item == nil {
+			return fmt.Errorf("unexpected %q frame", m.Type)
+		}
+		if m.Budget > 0 {
+			m.Item.Deadline = time.Now().Add(m.Budget)
+		}
+		jobs++
+		if jobs == *crashAfter {
+			fmt.Printf("💥 %s crashing with %s in flight\n", *name, m.Item.Source)
+			os.Exit(3)
+		}
+
+		reply := remoteMessage{Type: "result", Seq: m.Seq}
+		if reply.Result, err = processSafely(processor, m.Item); err != nil {
+			reply.Error = err.Error()
+			reply.Permanent = isPermanent(err)
+		}
+		if err := writeFrame(conn, reply); err != nil {
+			return err
+		}
+	}
+}
+
+// demonstrateRemoteWorkers starts three worker processes, one of which
+// crashes mid-job, and runs a batch through them
+func demonstrateRemoteWorkers() {
+	fmt.Println("\n=== Remote Workers Demo ===")
+
+	coordinator, err := ListenCoordinator("localhost:0")
+	if err != nil {
+		fmt.Printf("   ⚠️  %v\n", err)
+		return
+	}
+	defer coordinator.Close()
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Printf("   ⚠️  %v\n", err)
+		return
+	}
+
+	fmt.Printf("Coordinator on %s; starting 3 worker processes (remote-2 crashes on its 2nd job)\n\n", coordinator.Addr())
+	var procs []*exec.Cmd
+	for i := 1; i <= 3; i++ {
+		args := []string{"-processor", "simulated", "-fail-rate", "0", "worker",
+			"-connect", coordinator.Addr(), "-name", fmt.Sprintf("remote-%d", i)}
+		if i == 2 {
+			args = append(args, "-crash-after", "2")
+		}
+		cmd := exec.Command(self, args...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Start(); err != nil {
+			fmt.Printf("   ⚠️  %v\n", err)
+			return
+		}
+		procs = append(procs, cmd)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := coordinator.WaitForWorkers(ctx, 3); err != nil {
+		fmt.Printf("   ⚠️  Workers did not connect: %v\n", err)
+		return
+	}
+
+	// The same pool, output stage and statistics as the integrated demo;
+	// only the processing happens elsewhere
+	stats := NewStats()
+	defer stats.Close()
+	dlq := NewDeadLetterQueue("") // kept in memory
+	jobs := make(chan *APIResponse)
+	results := make(chan ProcessedData)
+	done := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 1; w <= 3; w++ {
+		wg.Add(1)
+		go processingWorker(w, jobs, results, coordinator, dlq, stats, &wg)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	go outputPipeline(results, done)
+
+	for i := 1; i <= 9; i++ {
+		source := fmt.Sprintf("API-%d", i)
+		jobs <- &APIResponse{Source: source, Data: "data-from-" + source}
+	}
+	close(jobs)
+	<-done
+
+	fmt.Println()
+	stats.Print()
+	coordinator.Print()
+	coordinator.Close()
+	for _, cmd := range procs {
+		cmd.Wait()
+	}
+
+	fmt.Println("\n💡 The pool's goroutines only carry jobs to other processes. A worker")
+	fmt.Println("   that disconnects mid-job loses nothing: the job goes to another one.")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeWorker connects to c, says hello and hands over the first job it gets
+// to answer, which returns the reply to send (nil to hang up instead)
+func fakeWorker(t *testing.T, c *Coordinator, answer func(job remoteMessage) *remoteMessage) {
+	conn, err := net.Dial("tcp", c.Addr())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+	if err := writeFrame(conn, remoteMessage{Type: "hello", Name: "fake", Processor: "simulated"}); err != nil {
+		t.Error(err)
+		return
+	}
+	var job remoteMessage
+	if err := readFrame(conn, &job); err != nil {
+		return // the coordinator closed
+	}
+	if reply := answer(job); reply != nil {
+		writeFrame(conn, *reply)
+	}
+}
+
+func TestRemoteBudgetReachesWorker(t *testing.T) {
+	c, err := ListenCoordinator("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const budget = 2 * time.Second
+	got := make(chan time.Duration, 1)
+	go fakeWorker(t, c, func(job remoteMessage) *remoteMessage {
+		got <- job.Budget
+		return &remoteMessage{Type: "result", Seq: job.Seq, Result: "ok"}
+	})
+
+	job := &APIResponse{Source: "API-1", Data: "data-from-API-1", Deadline: time.Now().Add(budget)}
+	if result, err := c.Process(job); result != "ok" || err != nil {
+		t.Fatalf("Process = %q, %v, want the worker's result", result, err)
+	}
+	if b := <-got; b <= 0 || b > budget {
+		t.Errorf("worker was given a budget of %v, want what is left of %v", b, budget)
+	}
+}
+
+func TestRemoteRequeuesAreCapped(t *testing.T) {
+	c, err := ListenCoordinator("localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Every worker hangs up with the job in flight
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			fakeWorker(t, c, func(remoteMessage) *remoteMessage { return nil })
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Process(&APIResponse{Source: "API-1", Data: "data-from-API-1"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, errWorkersLost) || !isPermanent(err) {
+			t.Errorf("Process = %v, want a permanent %v", err, errWorkersLost)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the job is still being passed around")
+	}
+	if n := c.requeued.Load(); n != maxRequeues {
+		t.Errorf("job re-queued %d times, want %d", n, maxRequeues)
+	}
+}
@@ -14,7 +14,7 @@ go version
 echo ""
 echo "=== Running the Concurrency Demo ==="
 cd "$(dirname "$0")"
-go run main.go
+go run .
 
 echo ""
 echo "=== Installation and Demo Complete! ==="
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// JOB SERVER: The worker pool behind an HTTP API, with results over SSE
+// ============================================================================
+
+// JobStatus is where a submitted job is
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// SubmittedJob is an item posted to the job server and what became of it
+type SubmittedJob struct {
+	ID        string     `json:"id"`
+	Source    string     `json:"source"`
+	Data      string     `json:"data"`
+	Processor string     `json:"processor"`
+	Status    JobStatus  `json:"status"`
+	Result    string     `json:"result,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Attempts  int        `json:"attempts,omitempty"`
+	Submitted time.Time  `json:"submitted"`
+	Finished  *time.Time `json:"finished,omitempty"`
+
+	processor Processor
+}
+
+// JobRequest is the body of POST /jobs
+type JobRequest struct {
+	Source    string `json:"source"`
+	Data      string `json:"data"`      // default: what the source would send
+	Processor string `json:"processor"` // default: the server's processor
+}
+
+// Errors the job server answers with 503
+var (
+	errQueueFull    = errors.New("job queue is full")
+	errShuttingDown = errors.New("server is shutting down")
+)
+
+// JobServer runs submitted jobs on a worker pool. Clients POST /jobs, poll
+// GET /jobs/{id} and can follow every status change on GET /events as
+// Server-Sent Events. It is an http.Handler, so httptest can serve it:
+//
+//	js := NewJobServer(ProcessFunc(simulateProcessing), 3, 100)
+//	js.Start()
+//	defer js.Close()
+//	ts := httptest.NewServer(js)
+type JobServer struct {
+	processor Processor
+	workers   int
+	queue     chan *SubmittedJob
+	mux       *http.ServeMux
+
+	mu          sync.Mutex
+	jobs        map[string]*SubmittedJob
+	next        int
+	closing     bool
+	subscribers map[*subscriber]struct{}
+
+	closed chan struct{} // closed by Close: ends the event streams
+	wg     sync.WaitGroup
+}
+
+// NewJobServer creates a server with workers workers and room for queueSize
+// waiting jobs. Start starts the workers.
+func NewJobServer(processor Processor, workers, queueSize int) *JobServer {
+	s := &JobServer{
+		processor:   processor,
+		workers:     workers,
+		queue:       make(chan *SubmittedJob, queueSize),
+		mux:         http.NewServeMux(),
+		jobs:        make(map[string]*SubmittedJob),
+		subscribers: make(map[*subscriber]struct{}),
+		closed:      make(chan struct{}),
+	}
+	s.mux.HandleFunc("/jobs", s.handleJobs)
+	s.mux.HandleFunc("/jobs/", s.handleJob)
+	s.mux.HandleFunc("/events", s.handleEvents)
+	return s
+}
+
+// Start starts the worker pool
+func (s *JobServer) Start() {
+	for w := 1; w <= s.workers; w++ {
+		s.wg.Add(1)
+		go s.worker(w)
+	}
+}
+
+// Close stops taking jobs, lets the workers finish the ones already queued
+// and ends every event stream
+func (s *JobServer) Close() {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return
+	}
+	s.closing = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	close(s.closed)
+}
+
+// worker processes queued jobs until the queue is closed (WORKER POOL)
+func (s *JobServer) worker(id int) {
+	defer s.wg.Done()
+	for job := range s.queue {
+		s.update(job, func(j *SubmittedJob) { j.Status = JobRunning })
+
+		item := &APIResponse{Source: job.Source, Data: job.Data, ID: job.ID}
+		processor := withFaults(job.processor, fmt.Sprintf("Worker-%d", id))
+		result, attempts, _, err := processWithRetry(item, processor)
+
+		status := JobDone
+		if err != nil {
+			status = JobFailed
+		}
+		s.update(job, func(j *SubmittedJob) {
+			finished := time.Now()
+			j.Status, j.Result, j.Attempts, j.Finished = status, result, attempts, &finished
+			if err != nil {
+				j.Error = err.Error()
+			}
+		})
+		fmt.Printf("   Worker-%d: %s %s after %d attempt(s)\n", id, job.ID, status, attempts)
+		watch.Beat("jobWorker")
+	}
+}
+
+// Submit queues a job and returns it as queued
+func (s *JobServer) Submit(req JobRequest) (SubmittedJob, error) {
+	processor := s.processor
+	if req.Processor != "" {
+		p, err := lookupProcessor(req.Processor)
+		if err != nil {
+			return SubmittedJob{}, err
+		}
+		processor = p
+	} else {
+		req.Processor = *processorName
+	}
+	if req.Data == "" {
+		req.Data = sampleData(processor, req.Source)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return SubmittedJob{}, errShuttingDown
+	}
+	s.next++
+	job := &SubmittedJob{
+		ID:        fmt.Sprintf("job-%d", s.next),
+		Source:    req.Source,
+		Data:      req.Data,
+		Processor: req.Processor,
+		Status:    JobQueued,
+		Submitted: time.Now(),
+		processor: processor,
+	}
+	select {
+	case s.queue <- job:
+	default:
+		s.next--
+		return SubmittedJob{}, errQueueFull
+	}
+	s.jobs[job.ID] = job
+	s.publish(*job)
+	return *job, nil
+}
+
+// Lookup returns a copy of a job
+func (s *JobServer) Lookup(id string) (SubmittedJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return SubmittedJob{}, false
+	}
+	return *job, true
+}
+
+// update changes a job under the lock and tells the subscribers
+func (s *JobServer) update(job *SubmittedJob, change func(j *SubmittedJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change(job)
+	s.publish(*job)
+}
+
+// subscriber is an event stream's view of the job updates
+type subscriber struct {
+	job    string // the only job it follows; "" for every job
+	events chan SubmittedJob
+}
+
+// publish sends a job's new state to its subscribers, with mu held. A
+// subscriber to every job that is not keeping up misses the event rather
+// than holding up the workers. One that follows a single job is sent only
+// that job's updates, at most one per status, so its buffer never fills.
+func (s *JobServer) publish(job SubmittedJob) {
+	for sub := range s.subscribers {
+		if sub.job != "" && sub.job != job.ID {
+			continue
+		}
+		select {
+		case sub.events <- job:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel of updates to job, or to every job if job is
+// ""; call the returned func to stop
+func (s *JobServer) subscribe(job string) (<-chan SubmittedJob, func()) {
+	sub := &subscriber{job: job, events: make(chan SubmittedJob, 64)}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+	return sub.events, func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+	}
+}
+
+func (s *JobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// PrintSummary reports how many jobs ended each way
+func (s *JobServer) PrintSummary() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[JobStatus]int)
+	for _, job := range s.jobs {
+		counts[job.Status]++
+	}
+	fmt.Printf("📊 %d job(s): %d done, %d failed\n", len(s.jobs), counts[JobDone], counts[JobFailed])
+}
+
+// writeJSON sends v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends {"error": ...}
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// handleJobs serves POST /jobs (submit) and GET /jobs (list)
+func (s *JobServer) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req JobRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("bad job: %v", err))
+			return
+		}
+		if req.Source == "" {
+			writeError(w, http.StatusBadRequest, errors.New("bad job: source is required"))
+			return
+		}
+		job, err := s.Submit(req)
+		switch {
+		case errors.Is(err, errQueueFull), errors.Is(err, errShuttingDown):
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusServiceUnavailable, err)
+		case err != nil:
+			writeError(w, http.StatusBadRequest, err)
+		default:
+			w.Header().Set("Location", "/jobs/"+job.ID)
+			writeJSON(w, http.StatusAccepted, job)
+		}
+	case http.MethodGet:
+		s.mu.Lock()
+		jobs := make([]SubmittedJob, 0, len(s.jobs))
+		for _, job := range s.jobs {
+			jobs = append(jobs, *job)
+		}
+		s.mu.Unlock()
+		sort.Slice(jobs, func(i, j int) bool { return jobNumber(jobs[i].ID) < jobNumber(jobs[j].ID) })
+		writeJSON(w, http.StatusOK, jobs)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use GET or POST"))
+	}
+}
+
+// jobNumber is n for "job-n", to list jobs in the order they came in
+func jobNumber(id string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(id, "job-"))
+	return n
+}
+
+// handleJob serves GET /jobs/{id}
+func (s *JobServer) handleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	job, ok := s.Lookup(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no job %q", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleEvents serves GET /events: every job update as a Server-Sent Event
+// named after the job's status. ?job=ID follows a single job, and ends the
+// stream once it has finished; an unknown ID gets a 404.
+func (s *JobServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+	only := r.URL.Query().Get("job")
+	events, unsubscribe := s.subscribe(only)
+	defer unsubscribe()
+
+	// Subscribed first, so a job that finishes now still sends its event
+	var current SubmittedJob
+	if only != "" {
+		job, ok := s.Lookup(only)
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no job %q", only))
+			return
+		}
+		current = job
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+
+	// A job that finished before we subscribed would never send an event
+	if current.Status == JobDone || current.Status == JobFailed {
+		writeEvent(w, current)
+		flusher.Flush()
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case job := <-events:
+			writeEvent(w, job)
+			flusher.Flush()
+			if only != "" && (job.Status == JobDone || job.Status == JobFailed) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// writeEvent writes a job as one Server-Sent Event
+func writeEvent(w http.ResponseWriter, job SubmittedJob) {
+	data, _ := json.Marshal(job)
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", job.ID, job.Status, data)
+}
+
+// runServer handles "serve": it runs a JobServer until Ctrl+C, then finishes
+// the queued jobs and shuts down
+func runServer(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	workers := fs.Int("workers", 3, "jobs processed at once")
+	queueSize := fs.Int("queue", 100, "jobs that may wait; more are turned away with 503")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	processor, err := lookupProcessor(*processorName)
+	if err != nil {
+		return err
+	}
+
+	js := NewJobServer(processor, *workers, *queueSize)
+	js.Start()
+	srv := &http.Server{Addr: *addr, Handler: js}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	failed := make(chan error, 1)
+	go func() { failed <- srv.ListenAndServe() }()
+
+	fmt.Printf("🌐 Job server on http://%s (workers: %d, -processor %s)\n", *addr, *workers, *processorName)
+	fmt.Printf("   curl -X POST %s/jobs -d '{\"source\":\"API-1\"}'\n", *addr)
+	fmt.Printf("   curl %s/jobs/job-1\n", *addr)
+	fmt.Printf("   curl -N %s/events\n", *addr)
+
+	select {
+	case err := <-failed:
+		js.Close()
+		return err
+	case <-interrupted.Done():
+	}
+
+	fmt.Println("\n🛑 Shutting down: finishing queued jobs")
+	js.Close() // ends the event streams, so Shutdown does not wait on them
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	js.PrintSummary()
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer serves a JobServer whose jobs upper-case their data once
+// release is closed
+func newTestServer(t *testing.T, workers, queueSize int) (*httptest.Server, *JobServer, chan struct{}) {
+	t.Helper()
+	release := make(chan struct{})
+	processor := ProcessFunc(func(job *APIResponse) (string, error) {
+		<-release
+		return strings.ToUpper(job.Data), nil
+	})
+	js := NewJobServer(processor, workers, queueSize)
+	js.Start()
+	ts := httptest.NewServer(js)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+		js.Close()
+		ts.Close()
+	})
+	return ts, js, release
+}
+
+// postJob submits a job and returns the response status and decoded job
+func postJob(t *testing.T, ts *httptest.Server, body string) (int, SubmittedJob) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/jobs", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var job SubmittedJob
+	if resp.StatusCode == http.StatusAccepted {
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, job
+}
+
+// getJob polls a job and returns the response status and decoded job
+func getJob(t *testing.T, ts *httptest.Server, id string) (int, SubmittedJob) {
+	t.Helper()
+	resp, err := http.Get(ts.URL + "/jobs/" + id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var job SubmittedJob
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, job
+}
+
+func TestSubmitAndPoll(t *testing.T) {
+	ts, _, release := newTestServer(t, 1, 10)
+
+	status, job := postJob(t, ts, `{"source":"API-1","data":"hello"}`)
+	if status != http.StatusAccepted {
+		t.Fatalf("POST /jobs = %d, want %d", status, http.StatusAccepted)
+	}
+	if job.ID != "job-1" || job.Status != JobQueued || job.Data != "hello" {
+		t.Errorf("POST /jobs returned %+v, want queued job-1", job)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != JobDone {
+		if time.Now().After(deadline) {
+			t.Fatalf("job still %s after 5s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if status, job = getJob(t, ts, "job-1"); status != http.StatusOK {
+			t.Fatalf("GET /jobs/job-1 = %d, want %d", status, http.StatusOK)
+		}
+	}
+	if job.Result != "HELLO" || job.Attempts != 1 || job.Finished == nil {
+		t.Errorf("finished job = %+v, want result HELLO after 1 attempt", job)
+	}
+}
+
+func TestBadJob(t *testing.T) {
+	ts, _, _ := newTestServer(t, 1, 10)
+	for _, body := range []string{`{}`, `{"source":`, `{"source":"API-1","processor":"nope"}`} {
+		if status, _ := postJob(t, ts, body); status != http.StatusBadRequest {
+			t.Errorf("POST /jobs %s = %d, want %d", body, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestUnknownJob(t *testing.T) {
+	ts, _, _ := newTestServer(t, 1, 10)
+	if status, _ := getJob(t, ts, "job-42"); status != http.StatusNotFound {
+		t.Errorf("GET /jobs/job-42 = %d, want %d", status, http.StatusNotFound)
+	}
+	resp, err := http.Get(ts.URL + "/events?job=job-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /events?job=job-42 = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	// No workers take jobs off the queue, so the second one does not fit
+	js := NewJobServer(ProcessFunc(simulateProcessing), 0, 1)
+	ts := httptest.NewServer(js)
+	defer ts.Close()
+	defer js.Close()
+
+	if status, _ := postJob(t, ts, `{"source":"API-1"}`); status != http.StatusAccepted {
+		t.Fatalf("first POST /jobs = %d, want %d", status, http.StatusAccepted)
+	}
+	resp, err := http.Post(ts.URL+"/jobs", "application/json", strings.NewReader(`{"source":"API-2"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("POST /jobs to a full queue = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("503 without a Retry-After header")
+	}
+}
+
+// readEvents reads Server-Sent Event names until the stream ends
+func readEvents(t *testing.T, resp *http.Response) []string {
+	t.Helper()
+	var names []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestEventsForOneJob(t *testing.T) {
+	ts, _, release := newTestServer(t, 1, 200)
+
+	// The jobs ahead of the one being followed must not crowd out its updates
+	for i := 0; i < 100; i++ {
+		postJob(t, ts, `{"source":"API-2"}`)
+	}
+	_, job := postJob(t, ts, `{"source":"API-1"}`)
+
+	resp, err := http.Get(ts.URL + "/events?job=" + job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+	close(release)
+
+	done := make(chan []string, 1)
+	go func() { done <- readEvents(t, resp) }()
+	select {
+	case names := <-done:
+		if len(names) == 0 || names[len(names)-1] != string(JobDone) {
+			t.Errorf("events = %q, want the stream to end with done", names)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream did not end after the job finished")
+	}
+
+	// Once it has finished, following the job gets just its last state
+	resp, err = http.Get(ts.URL + "/events?job=" + job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if names := readEvents(t, resp); len(names) != 1 || names[0] != string(JobDone) {
+		t.Errorf("events for a finished job = %q, want just done", names)
+	}
+}
+
+func TestPublishFiltersBeforeQueueing(t *testing.T) {
+	js := NewJobServer(ProcessFunc(simulateProcessing), 0, 1)
+	defer js.Close()
+	all, stopAll := js.subscribe("")
+	defer stopAll()
+	one, stopOne := js.subscribe("job-7")
+	defer stopOne()
+
+	// Nobody reads: far more updates than a buffer holds, then job-7's
+	js.mu.Lock()
+	for i := 0; i < 1000; i++ {
+		js.publish(SubmittedJob{ID: "job-1", Status: JobRunning})
+	}
+	js.publish(SubmittedJob{ID: "job-7", Status: JobDone})
+	js.mu.Unlock()
+
+	if got := len(all); got != cap(all) {
+		t.Errorf("subscriber to every job holds %d updates, want a full buffer of %d", got, cap(all))
+	}
+	if got := len(one); got != 1 {
+		t.Fatalf("subscriber to job-7 holds %d updates, want only job-7's", got)
+	}
+	if job := <-one; job.ID != "job-7" || job.Status != JobDone {
+		t.Errorf("subscriber to job-7 got %+v", job)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// REQUEST COALESCING: Concurrent fetches of the same key share one call
+// ============================================================================
+
+// flight is a call in progress; everyone asking for its key waits on it
+type flight[T any] struct {
+	done  chan struct{} // closed when the call returns
+	value T
+	err   error
+}
+
+// FlightGroup merges concurrent calls for the same key: the first caller
+// runs the function, callers arriving while it runs wait and get the same
+// result. Once the call returns, the next caller starts a fresh one - nothing
+// is cached.
+type FlightGroup[T any] struct {
+	mu      sync.Mutex
+	flights map[string]*flight[T]
+
+	calls, deduplicated atomic.Int64
+}
+
+// NewFlightGroup creates an empty group
+func NewFlightGroup[T any]() *FlightGroup[T] {
+	return &FlightGroup[T]{flights: make(map[string]*flight[T])}
+}
+
+// Do runs fn for key, unless a call for key is already running, in which case
+// it waits for that call instead. shared is true when the result came from
+// another caller's call. If fn panics, the panic is passed on to the caller that
+// ran it, and the callers that waited get an error wrapping errFlightPanicked.
+func (g *FlightGroup[T]) Do(key string, fn func() (T, error)) (value T, err error, shared bool) {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		g.deduplicated.Add(1)
+		<-f.done
+		return f.value, f.err, true
+	}
+	f := &flight[T]{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	g.calls.Add(1)
+	// Even if fn panics, the waiters must be let go and the key freed: they
+	// get an error, and the panic carries on up this caller's stack
+	defer func() {
+		r := recover()
+		if r != nil {
+			f.err = fmt.Errorf("%w: %s: %v", errFlightPanicked, key, r)
+		}
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+		if r != nil {
+			panic(r)
+		}
+	}()
+	f.value, f.err = fn()
+	return f.value, f.err, false
+}
+
+// errFlightPanicked is what callers waiting on a call get when it panicked
+var errFlightPanicked = errors.New("coalesced call panicked")
+
+// Calls returns how many times the group actually ran a function
+func (g *FlightGroup[T]) Calls() int64 { return g.calls.Load() }
+
+// Deduplicated returns how many callers got another caller's result
+func (g *FlightGroup[T]) Deduplicated() int64 { return g.deduplicated.Load() }
+
+// fetchAll fetches every source concurrently, through group if it is not
+// nil, and returns how long that took and how many fetches really ran
+func fetchAll(sources []string, group *FlightGroup[string]) (time.Duration, int64) {
+	var fetches atomic.Int64
+	fetch := func(source string) (string, error) {
+		fetches.Add(1)
+		return fetchSource(source), nil
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(src string) {
+			defer wg.Done()
+			if group == nil {
+				fetch(src)
+				return
+			}
+			group.Do(src, func() (string, error) { return fetch(src) })
+		}(source)
+	}
+	wg.Wait()
+	return time.Since(start), fetches.Load()
+}
+
+// demonstrateSingleflight fetches a source list with repeats, first with every
+// request on its own and then coalesced
+func demonstrateSingleflight() {
+	fmt.Println("\n=== Request Coalescing Demo (Singleflight) ===")
+
+	sources := []string{"API-1", "API-2", "API-1", "Database", "API-1", "API-2", "Database", "API-1"}
+	fmt.Printf("Requests: %v\n", sources)
+
+	elapsed, fetches := fetchAll(sources, nil)
+	fmt.Printf("\n🔁 Every request fetches:  %d fetches in %v\n", fetches, elapsed.Round(time.Millisecond))
+
+	group := NewFlightGroup[string]()
+	elapsed, fetches = fetchAll(sources, group)
+	fmt.Printf("🤝 Coalesced by source:    %d fetches in %v (%d requests shared a fetch)\n",
+		fetches, elapsed.Round(time.Millisecond), group.Deduplicated())
+
+	fmt.Println("\n💡 Concurrent requests for the same key wait for the one fetch already")
+	fmt.Println("   running instead of starting their own; the backend sees each source once.")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// joined waits until n callers are waiting on another caller's call
+func joined(t *testing.T, g *FlightGroup[string], n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for g.Deduplicated() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d callers joined the call", g.Deduplicated(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// result is what one Do call returned
+type result struct {
+	value  string
+	err    error
+	shared bool
+}
+
+func TestFlightGroupCoalesces(t *testing.T) {
+	const callers = 10
+	g := NewFlightGroup[string]()
+	release := make(chan struct{})
+	fn := func() (string, error) {
+		<-release
+		return "data-from-API-1", nil
+	}
+
+	results := make(chan result, callers)
+	var wg sync.WaitGroup
+	go func() {
+		value, err, shared := g.Do("API-1", fn)
+		results <- result{value, err, shared}
+	}()
+	for g.Calls() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err, shared := g.Do("API-1", fn)
+			results <- result{value, err, shared}
+		}()
+	}
+	joined(t, g, callers-1)
+	close(release)
+	wg.Wait()
+
+	shared := 0
+	for i := 0; i < callers; i++ {
+		r := <-results
+		if r.value != "data-from-API-1" || r.err != nil {
+			t.Errorf("Do = %q, %v, want the one call's result", r.value, r.err)
+		}
+		if r.shared {
+			shared++
+		}
+	}
+	if g.Calls() != 1 || shared != callers-1 {
+		t.Errorf("%d call(s) for %d callers with %d shared, want 1 call shared by %d", g.Calls(), callers, shared, callers-1)
+	}
+
+	// The call is over, so the next caller starts a fresh one
+	if _, _, shared := g.Do("API-1", func() (string, error) { return "again", nil }); shared || g.Calls() != 2 {
+		t.Errorf("Do after the call returned: shared = %v, calls = %d, want a fresh call", shared, g.Calls())
+	}
+}
+
+func TestFlightGroupPanic(t *testing.T) {
+	const waiters = 5
+	g := NewFlightGroup[string]()
+	release := make(chan struct{})
+
+	recovered := make(chan any, 1)
+	go func() {
+		defer func() { recovered <- recover() }()
+		g.Do("API-1", func() (string, error) {
+			<-release
+			panic("fetch blew up")
+		})
+	}()
+	for g.Calls() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	results := make(chan result, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			value, err, shared := g.Do("API-1", func() (string, error) { return "not me", nil })
+			results <- result{value, err, shared}
+		}()
+	}
+	joined(t, g, waiters)
+	close(release)
+
+	if r := <-recovered; r != "fetch blew up" {
+		t.Errorf("the caller that ran fn recovered %v, want its panic", r)
+	}
+	for i := 0; i < waiters; i++ {
+		select {
+		case r := <-results:
+			if !errors.Is(r.err, errFlightPanicked) || !r.shared {
+				t.Errorf("waiter got %q, %v (shared %v), want errFlightPanicked", r.value, r.err, r.shared)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("waiters still blocked after the call panicked")
+		}
+	}
+
+	// The key was freed: a later call runs instead of hanging
+	done := make(chan string, 1)
+	go func() {
+		value, _, _ := g.Do("API-1", func() (string, error) { return "fresh", nil })
+		done <- value
+	}()
+	select {
+	case value := <-done:
+		if value != "fresh" {
+			t.Errorf("Do after the panic = %q, want a fresh call", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do for the key hangs after its call panicked")
+	}
+}
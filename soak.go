@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"golang-concurrency-demo/faults"
+	"golang-concurrency-demo/leakcheck"
+	"golang-concurrency-demo/ledger"
+)
+
+// ============================================================================
+// SOAK MODE: The integrated pipeline, thousands of times, with random faults
+// ============================================================================
+
+// soakRun is the outcome of one pass of the pipeline
+type soakRun struct {
+	seed     int64
+	spec     string
+	problems []string // broken invariants; none means the run passed
+	hung     bool     // the pipeline never finished, so its goroutines are still around
+	output   string   // what the run printed
+}
+
+// Faults a soak run may pick for a target
+var soakFaultKinds = []faults.Kind{faults.Error, faults.Spike, faults.Hang, faults.Panic, faults.Partial, faults.Slow}
+
+// randomFaultSpec makes up a -faults spec for a run: about half of the
+// sources, and one of the workers, misbehave in one way each
+func randomFaultSpec(rng *rand.Rand) string {
+	targets := append([]string{}, integratedSources...)
+	targets = append(targets, fmt.Sprintf("Worker-%d", 1+rng.Intn(3)))
+
+	var rules []string
+	for _, target := range targets {
+		if rng.Intn(2) == 0 {
+			continue
+		}
+		kind := soakFaultKinds[rng.Intn(len(soakFaultKinds))]
+		rule := fmt.Sprintf("%s=%s:%.2f", target, kind, 0.1+0.4*rng.Float64())
+		switch kind {
+		case faults.Spike:
+			rule += fmt.Sprintf(":%dms", 100+rng.Intn(800))
+		case faults.Hang:
+			rule += fmt.Sprintf(":%dms", 1000+rng.Intn(2000))
+		case faults.Slow:
+			rule += fmt.Sprintf(":%dms", 50+rng.Intn(250))
+		}
+		rules = append(rules, rule)
+	}
+	return strings.Join(rules, ";")
+}
+
+// runSoakOnce runs one integrated cycle with seed and spec and checks that it finished, accounted for every source and left no
+// goroutines behind
+func runSoakOnce(seed int64, spec string, timeout time.Duration) soakRun {
+	run := soakRun{seed: seed, spec: spec}
+	rand.Seed(seed)
+	var err error
+	if injector, err = faults.Parse(spec, seed); err != nil {
+		run.problems = append(run.problems, err.Error())
+		return run
+	}
+
+	snapshot := leakcheck.Take()
+	accounts = ledger.New()
+	stats := NewStats()
+	defer stats.Close()
+	dlq := NewDeadLetterQueue("") // never saved
+	cache := NewTTLCache[*APIResponse](0, 0, len(integratedSources))
+
+	results := make(chan int, 1)
+	go func() {
+		results <- runIntegratedCycle(integratedSources, integratedPriorities, cache, nil,
+			ProcessFunc(simulateProcessing), dlq, stats)
+	}()
+
+	var out int
+	select {
+	case out = <-results:
+	case <-time.After(timeout):
+		run.hung = true
+		run.problems = append(run.problems, fmt.Sprintf("pipeline did not finish within %v", timeout))
+		return run
+	}
+	cache.Wait()
+
+	// Every source ends up as exactly one result, dead letter or expired item
+	if err := accounts.Check(); err != nil {
+		run.problems = append(run.problems, err.Error())
+	}
+	if totals := accounts.Totals(); totals.In != len(integratedSources) {
+		run.problems = append(run.problems, fmt.Sprintf("%d sources but %d items went in", len(integratedSources), totals.In))
+	}
+	dead, processed := stats.deadLettered.Load(), stats.totalProcessed.Load()
+	if processed != int64(out) {
+		run.problems = append(run.problems, fmt.Sprintf("%d items processed but %d results came out", processed, out))
+	}
+	if int64(dlq.Len()) != dead {
+		run.problems = append(run.problems, fmt.Sprintf("%d dead letters counted but %d queued", dead, dlq.Len()))
+	}
+	if leaks := snapshot.Leaked(); len(leaks) > 0 {
+		run.problems = append(run.problems, strings.TrimSpace(leakcheck.Report(leaks)))
+	}
+	return run
+}
+
+// raceEnabled reports whether the binary was built with -race
+func raceEnabled() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return false
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "-race" {
+			return setting.Value == "true"
+		}
+	}
+	return false
+}
+
+// tail returns the last n lines of text
+func tail(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printSoakFailure explains a failed run and how to repeat it
+func printSoakFailure(w io.Writer, run soakRun) {
+	fmt.Fprintf(w, "\n❌ Run with seed %d failed:\n", run.seed)
+	for _, problem := range run.problems {
+		fmt.Fprint(w, indent(problem, "   "))
+	}
+	if run.spec != "" {
+		fmt.Fprintf(w, "   Faults: %s\n", run.spec)
+	}
+	repeat := "go run -race ."
+	if *faultSpec != "" {
+		repeat += fmt.Sprintf(" -faults '%s'", *faultSpec)
+	}
+	fmt.Fprintf(w, "   Repeat: %s soak -replay %d\n", repeat, run.seed)
+	fmt.Fprintln(w, "           (the same faults; latencies and scheduling still vary, so it may take a few tries)")
+	if run.output != "" {
+		fmt.Fprintln(w, "   Last output:")
+		fmt.Fprint(w, indent(tail(run.output, 15), "   │ "))
+	}
+}
+
+// runSoak handles "soak": it runs the integrated pipeline again and again,
+// each time with a new seed and (unless -faults is given) new faults, until
+// -runs runs or -for has passed
+func runSoak(args []string) error {
+	fs := flag.NewFlagSet("soak", flag.ContinueOnError)
+	runs := fs.Int("runs", 100, "how many runs (ignored with -for)")
+	duration := fs.Duration("for", 0, "keep going this long instead of a number of runs")
+	replay := fs.Int64("replay", 0, "repeat only the run with this seed's faults, printing its output (the top-level -seed seeds the whole soak)")
+	timeout := fs.Duration("timeout", 15*time.Second, "how long one run may take before it counts as hung")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	replaying := false // any seed can be replayed, 0 included
+	fs.Visit(func(f *flag.Flag) { replaying = replaying || f.Name == "replay" })
+
+	// Each run's faults come from its seed unless -faults fixes them
+	specFor := func(seed int64) string {
+		if *faultSpec != "" {
+			return *faultSpec
+		}
+		return randomFaultSpec(rand.New(rand.NewSource(seed)))
+	}
+
+	if replaying {
+		spec := specFor(*replay)
+		fmt.Printf("🔁 Repeating seed %d, faults: %s\n", *replay, spec)
+		run := runSoakOnce(*replay, spec, *timeout)
+		if len(run.problems) > 0 {
+			printSoakFailure(os.Stdout, run)
+			return errors.New("run failed")
+		}
+		fmt.Println("\n✅ Run passed")
+		return nil
+	}
+
+	fmt.Println("🧪 Soak: the integrated pipeline, again and again, with random faults")
+	if !raceEnabled() {
+		fmt.Println("   ⚠️  Not built with -race: data races will go unnoticed (go run -race . soak)")
+	}
+
+	// Runs print into a file, so a failure can show what its run printed
+	capture, err := os.CreateTemp("", "soak-*.log")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(capture.Name())
+	defer capture.Close()
+	stdout := os.Stdout
+	os.Stdout = capture
+	hung := false
+	defer func() {
+		if !hung { // a hung run's goroutines may still be printing
+			os.Stdout = stdout
+		}
+	}()
+	say := func(format string, a ...any) { fmt.Fprintf(stdout, format, a...) }
+
+	// The run seeds come from -seed, so a whole soak can be repeated too
+	start := time.Now()
+	seeds := rand.New(rand.NewSource(*seed))
+	say("   Seeds from -seed %d\n", *seed)
+	total, failed := 0, 0
+	for (*duration > 0 && time.Since(start) < *duration) || (*duration == 0 && total < *runs) {
+		runSeed := seeds.Int63()
+		capture.Truncate(0)
+		capture.Seek(0, io.SeekStart)
+
+		run := runSoakOnce(runSeed, specFor(runSeed), *timeout)
+		total++
+		if len(run.problems) > 0 {
+			failed++
+			// Read through a new handle: a hung run may still be writing
+			if output, err := os.ReadFile(capture.Name()); err == nil {
+				run.output = string(output)
+			}
+			printSoakFailure(stdout, run)
+			if hung = run.hung; hung {
+				say("\n🛑 Stopping: the hung run's goroutines would disturb every run after it\n")
+				break
+			}
+		}
+		if total%25 == 0 {
+			say("   %d runs, %d failed (%v)\n", total, failed, time.Since(start).Round(time.Second))
+		}
+	}
+
+	say("\n📊 %d runs in %v, %d failed\n", total, time.Since(start).Round(time.Second), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d runs failed", failed, total)
+	}
+	say("✅ Every run accounted for every source and left no goroutines behind\n")
+	return nil
+}
@@ -0,0 +1,377 @@
+// Package timeline records when goroutines start and finish pieces of work
+// and draws them as a Gantt chart: one lane per worker (or task, or source),
+// one bar per job, with the idle gaps and timeouts in between.
+//
+//	rec := timeline.New("Worker pool")
+//	...
+//	span := rec.Start("Worker 3", "job 7")
+//	doWork()
+//	span.End()
+//	...
+//	rec.Save("timeline.html") // open in a browser
+//
+// A nil *Recorder (and the nil *Span it returns) is valid and records
+// nothing, so demos can record unconditionally.
+package timeline
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind says what a span was spent doing, and picks its colour
+type Kind string
+
+const (
+	KindWork    Kind = "work"    // doing the job
+	KindWait    Kind = "wait"    // blocked: waiting for a slot, a lock, a channel
+	KindTimeout Kind = "timeout" // gave up waiting
+	KindError   Kind = "error"   // the job failed
+)
+
+// kindColors are the bar colours, also shown in the legend
+var kindColors = []struct {
+	kind  Kind
+	color string
+}{
+	{KindWork, "#4e9a06"},
+	{KindWait, "#c4a000"},
+	{KindTimeout, "#cc0000"},
+	{KindError, "#75507b"},
+}
+
+// Span is one piece of work (or waiting) on one lane
+type Span struct {
+	Lane  string
+	Label string
+	Kind  Kind
+	From  time.Time
+	To    time.Time
+
+	rec *Recorder
+}
+
+// Recorder collects finished spans. It is safe for concurrent use.
+type Recorder struct {
+	title string
+
+	mu      sync.Mutex
+	started time.Time
+	lanes   []string // in the order they first appeared
+	seen    map[string]bool
+	spans   []Span
+}
+
+// New creates a recorder; the chart's time axis starts now
+func New(title string) *Recorder {
+	return &Recorder{title: title, started: time.Now(), seen: make(map[string]bool)}
+}
+
+// Start begins a span of work on a lane. Call End (or EndAs) when it is over;
+// spans that never end are not drawn.
+func (r *Recorder) Start(lane, label string) *Span {
+	if r == nil {
+		return nil
+	}
+	r.addLane(lane)
+	return &Span{Lane: lane, Label: label, Kind: KindWork, From: time.Now(), rec: r}
+}
+
+// End finishes the span as work
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.EndAs(s.Kind)
+}
+
+// EndAs finishes the span as the given kind, e.g. KindTimeout
+func (s *Span) EndAs(kind Kind) {
+	if s == nil || s.rec == nil {
+		return
+	}
+	s.rec.Add(s.Lane, s.Label, kind, s.From, time.Now())
+}
+
+// Add records a span that has already happened
+func (r *Recorder) Add(lane, label string, kind Kind, start, end time.Time) {
+	if r == nil {
+		return
+	}
+	r.addLane(lane)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, Span{Lane: lane, Label: label, Kind: kind, From: start, To: end})
+}
+
+// addLane makes sure a lane is drawn, even before any of its spans end
+func (r *Recorder) addLane(lane string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.seen[lane] {
+		r.seen[lane] = true
+		r.lanes = append(r.lanes, lane)
+	}
+}
+
+// Spans returns a copy of the finished spans
+func (r *Recorder) Spans() []Span {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Span(nil), r.spans...)
+}
+
+// Save writes the chart to an HTML file
+func (r *Recorder) Save(path string) error {
+	if r == nil {
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteHTML(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Chart layout, in pixels
+const (
+	labelWidth = 170
+	chartWidth = 960
+	rowHeight  = 20
+	rowGap     = 8
+	axisHeight = 30
+)
+
+// lane is one lane's spans, split into rows so overlapping spans (two
+// goroutines sharing a worker name, say) are drawn one above the other
+type lane struct {
+	name string
+	rows [][]Span
+	busy time.Duration // union of the lane's spans
+}
+
+// layout groups the spans into lanes and rows
+func (r *Recorder) layout() ([]lane, time.Time, time.Duration) {
+	r.mu.Lock()
+	spans := append([]Span(nil), r.spans...)
+	names := append([]string(nil), r.lanes...)
+	start := r.started
+	r.mu.Unlock()
+
+	end := start
+	for _, s := range spans {
+		if s.To.After(end) {
+			end = s.To
+		}
+	}
+	total := end.Sub(start)
+	if total <= 0 {
+		total = time.Millisecond
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].From.Before(spans[j].From) })
+	lanes := make([]lane, len(names))
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		lanes[i].name = name
+		index[name] = i
+	}
+	for _, s := range spans {
+		l := &lanes[index[s.Lane]]
+		placed := false
+		for i, row := range l.rows {
+			if !row[len(row)-1].To.After(s.From) {
+				l.rows[i] = append(row, s)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			l.rows = append(l.rows, []Span{s})
+		}
+	}
+
+	for i := range lanes {
+		lanes[i].busy = busyTime(lanes[i].rows)
+	}
+	return lanes, start, total
+}
+
+// busyTime is how long at least one span of the lane was running
+func busyTime(rows [][]Span) time.Duration {
+	var all []Span
+	for _, row := range rows {
+		all = append(all, row...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].From.Before(all[j].From) })
+
+	var busy time.Duration
+	var curStart, curEnd time.Time
+	for i, s := range all {
+		if i == 0 || s.From.After(curEnd) {
+			busy += curEnd.Sub(curStart)
+			curStart, curEnd = s.From, s.To
+			continue
+		}
+		if s.To.After(curEnd) {
+			curEnd = s.To
+		}
+	}
+	return busy + curEnd.Sub(curStart)
+}
+
+// WriteHTML writes a self-contained HTML page with the chart as inline SVG.
+// Hovering a bar or gap shows what it was and how long it took.
+func (r *Recorder) WriteHTML(w io.Writer) error {
+	if r == nil {
+		return nil
+	}
+	lanes, start, total := r.layout()
+
+	x := func(t time.Time) float64 {
+		return labelWidth + float64(t.Sub(start))/float64(total)*chartWidth
+	}
+	ms := func(d time.Duration) string { return d.Round(time.Millisecond).String() }
+
+	rows := 0
+	for _, l := range lanes {
+		rows += len(l.rows)
+		if len(l.rows) == 0 {
+			rows++
+		}
+	}
+	height := axisHeight + rows*rowHeight + len(lanes)*rowGap + 10
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n",
+		labelWidth+chartWidth+20, height)
+
+	// Time axis, with a gridline every tick
+	tick := niceTick(total)
+	for t := time.Duration(0); t <= total; t += tick {
+		px := labelWidth + float64(t)/float64(total)*chartWidth
+		fmt.Fprintf(&svg, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#ddd"/>`+"\n", px, axisHeight-5, px, height)
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%d" text-anchor="middle" fill="#555">%s</text>`+"\n", px, axisHeight-10, t)
+	}
+
+	y := axisHeight
+	for i, l := range lanes {
+		laneRows := len(l.rows)
+		if laneRows == 0 {
+			laneRows = 1
+		}
+		laneHeight := laneRows * rowHeight
+		if i%2 == 0 {
+			fmt.Fprintf(&svg, `<rect x="0" y="%d" width="%d" height="%d" fill="#f6f6f6"/>`+"\n",
+				y, labelWidth+chartWidth+20, laneHeight+rowGap)
+		}
+		utilisation := 100 * float64(l.busy) / float64(total)
+		fmt.Fprintf(&svg, `<text x="6" y="%d"><title>busy %s of %s</title>%s <tspan fill="#888">%.0f%%</tspan></text>`+"\n",
+			y+rowGap/2+14, ms(l.busy), ms(total), html.EscapeString(l.name), utilisation)
+
+		for ri, row := range l.rows {
+			rowY := y + rowGap/2 + ri*rowHeight
+
+			// Idle gaps between consecutive spans of the row
+			prevEnd := start
+			for _, s := range row {
+				if gap := s.From.Sub(prevEnd); gap > time.Millisecond {
+					fmt.Fprintf(&svg, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="url(#idle)"><title>idle %s</title></rect>`+"\n",
+						x(prevEnd), rowY+2, x(s.From)-x(prevEnd), rowHeight-4, ms(gap))
+				}
+				prevEnd = s.To
+			}
+
+			for _, s := range row {
+				width := x(s.To) - x(s.From)
+				if width < 1 {
+					width = 1
+				}
+				fmt.Fprintf(&svg, `<rect x="%.1f" y="%d" width="%.1f" height="%d" rx="2" fill="%s"><title>%s: %s (%s) %s → %s</title></rect>`+"\n",
+					x(s.From), rowY+2, width, rowHeight-4, colorOf(s.Kind),
+					html.EscapeString(l.name), html.EscapeString(s.Label), s.Kind,
+					ms(s.From.Sub(start)), ms(s.To.Sub(start)))
+				if width > float64(7*len(s.Label)) {
+					fmt.Fprintf(&svg, `<text x="%.1f" y="%d" fill="#fff" pointer-events="none">%s</text>`+"\n",
+						x(s.From)+3, rowY+14, html.EscapeString(s.Label))
+				}
+			}
+		}
+		y += laneHeight + rowGap
+	}
+
+	svg.WriteString(`<defs><pattern id="idle" width="6" height="6" patternUnits="userSpaceOnUse" patternTransform="rotate(45)">` +
+		`<rect width="6" height="6" fill="#eee"/><line x1="0" y1="0" x2="0" y2="6" stroke="#ccc" stroke-width="2"/></pattern></defs>` + "\n")
+	svg.WriteString("</svg>\n")
+
+	var legend strings.Builder
+	for _, kc := range kindColors {
+		fmt.Fprintf(&legend, `<span><i style="background:%s"></i>%s</span>`, kc.color, kc.kind)
+	}
+	legend.WriteString(`<span><i class="idle"></i>idle</span>`)
+
+	_, err := fmt.Fprintf(w, htmlPage, html.EscapeString(r.title), html.EscapeString(r.title),
+		len(r.Spans()), len(lanes), ms(total), legend.String(), svg.String())
+	return err
+}
+
+// colorOf returns the bar colour of a kind
+func colorOf(kind Kind) string {
+	for _, kc := range kindColors {
+		if kc.kind == kind {
+			return kc.color
+		}
+	}
+	return "#888"
+}
+
+// niceTick picks an axis step that gives roughly ten ticks
+func niceTick(total time.Duration) time.Duration {
+	for _, step := range []time.Duration{
+		time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+		10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+		100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+		time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	} {
+		if total/step <= 12 {
+			return step
+		}
+	}
+	return time.Minute
+}
+
+const htmlPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s - timeline</title>
+<style>
+body { font-family: sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+p { color: #555; margin-top: 0; }
+.legend span { margin-right: 16px; }
+.legend i { display: inline-block; width: 12px; height: 12px; margin-right: 4px; vertical-align: -1px; border-radius: 2px; }
+.legend i.idle { background: repeating-linear-gradient(45deg, #eee, #eee 3px, #ccc 3px, #ccc 5px); }
+svg rect:hover { opacity: 0.75; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+<p>%d spans on %d lanes over %s. Hover a bar for details; the percentage next to a lane is how busy it was.</p>
+<p class="legend">%s</p>
+%s</body>
+</html>
+`
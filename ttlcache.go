@@ -0,0 +1,154 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// RESULT CACHE: TTL, LRU eviction and stale-while-revalidate for fetches
+// ============================================================================
+
+// CacheResult says how a TTLCache answered a Get
+type CacheResult int
+
+const (
+	CacheMiss  CacheResult = iota // not cached (or too old): fetched by the caller
+	CacheHit                      // cached and fresh
+	CacheStale                    // expired but served anyway while a refresh runs
+)
+
+func (r CacheResult) String() string {
+	switch r {
+	case CacheHit:
+		return "hit"
+	case CacheStale:
+		return "stale"
+	default:
+		return "miss"
+	}
+}
+
+// ttlEntry is one cached value and its place in the LRU list
+type ttlEntry[V any] struct {
+	key        string
+	value      V
+	fetchedAt  time.Time
+	refreshing bool
+	element    *list.Element
+}
+
+// TTLCache caches fetched values for ttl. After that an entry is stale: for
+// another staleFor it is still served - instantly - while one background
+// refresh fetches a new value (stale-while-revalidate); after that it counts
+// as missing. When more than capacity keys are cached, the least recently
+// used one is evicted. It is safe for concurrent use.
+type TTLCache[V any] struct {
+	ttl      time.Duration
+	staleFor time.Duration
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*ttlEntry[V]
+	lru     *list.List // front = most recently used
+
+	refreshes sync.WaitGroup // background refreshes in flight
+	evictions atomic.Int64
+}
+
+// NewTTLCache creates an empty cache
+func NewTTLCache[V any](ttl, staleFor time.Duration, capacity int) *TTLCache[V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &TTLCache[V]{
+		ttl:      ttl,
+		staleFor: staleFor,
+		capacity: capacity,
+		entries:  make(map[string]*ttlEntry[V]),
+		lru:      list.New(),
+	}
+}
+
+// Get returns the cached value for key, calling fetch when there is none (or
+// it is too old). A stale value is returned at once and fetch runs in the
+// background to replace it. Failed fetches are not cached.
+func (c *TTLCache[V]) Get(key string, fetch func() (V, error)) (V, CacheResult, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		age := time.Since(e.fetchedAt)
+		switch {
+		case age < c.ttl:
+			c.lru.MoveToFront(e.element)
+			c.mu.Unlock()
+			return e.value, CacheHit, nil
+		case age < c.ttl+c.staleFor:
+			c.lru.MoveToFront(e.element)
+			if !e.refreshing {
+				e.refreshing = true
+				c.refreshes.Add(1)
+				go c.refresh(key, fetch)
+			}
+			c.mu.Unlock()
+			return e.value, CacheStale, nil
+		}
+	}
+	c.mu.Unlock()
+
+	value, err := fetch()
+	if err == nil {
+		c.put(key, value)
+	}
+	return value, CacheMiss, err
+}
+
+// refresh replaces a stale entry in the background. If the fetch fails the
+// stale value stays until it expires for good.
+func (c *TTLCache[V]) refresh(key string, fetch func() (V, error)) {
+	defer c.refreshes.Done()
+	value, err := fetch()
+	if err == nil {
+		c.put(key, value)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.refreshing = false
+	}
+}
+
+// put stores a freshly fetched value and evicts the least recently used
+// entries beyond capacity
+func (c *TTLCache[V]) put(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.value, e.fetchedAt, e.refreshing = value, time.Now(), false
+		c.lru.MoveToFront(e.element)
+		return
+	}
+	e := &ttlEntry[V]{key: key, value: value, fetchedAt: time.Now()}
+	e.element = c.lru.PushFront(e)
+	c.entries[key] = e
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Remove(c.lru.Back()).(*ttlEntry[V])
+		delete(c.entries, oldest.key)
+		c.evictions.Add(1)
+	}
+}
+
+// Len returns how many keys are cached, fresh or stale
+func (c *TTLCache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Evictions returns how many entries were dropped to stay within capacity
+func (c *TTLCache[V]) Evictions() int64 { return c.evictions.Load() }
+
+// Wait blocks until the background refreshes in flight have finished
+func (c *TTLCache[V]) Wait() { c.refreshes.Wait() }
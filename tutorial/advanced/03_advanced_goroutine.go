@@ -1,13 +1,43 @@
-package main
+// Package advanced is level 3 of the tutorial: production patterns.
+package advanced
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"math/rand"
 	"sync"
 	"time"
+
+	"golang-concurrency-demo/tutorial/lesson"
 )
 
+// source is shown next to an example's output by the interactive runner
+//
+//go:embed 03_advanced_goroutine.go
+var source string
+
+func init() {
+	lesson.Register(lesson.Lesson{
+		Order:       3,
+		Name:        "advanced",
+		Title:       "Advanced Patterns",
+		Description: "Worker pools, fan-out/fan-in, context cancellation and timeouts, rate limiting, semaphores, error groups",
+		Intro:       intro,
+		Examples: []lesson.Example{
+			{Name: "workerPoolExample", Title: "Worker Pool Pattern", Run: workerPoolExample},
+			{Name: "fanInFanOutExample", Title: "Fan-Out / Fan-In Pattern", Run: fanInFanOutExample},
+			{Name: "contextCancellationExample", Title: "Context Cancellation", Run: contextCancellationExample},
+			{Name: "contextTimeoutExample", Title: "Context with Timeout", Run: contextTimeoutExample},
+			{Name: "rateLimitingExample", Title: "Rate Limiting", Run: rateLimitingExample},
+			{Name: "semaphoreExample", Title: "Semaphore Pattern", Run: semaphoreExample},
+			{Name: "errorGroupExample", Title: "Error Group Pattern", Run: errorGroupExample},
+		},
+		Outro:  outro,
+		Source: source,
+	})
+}
+
 // Example 1: Worker Pool Pattern
 type Job struct {
 	ID   int
@@ -335,21 +365,13 @@ func errorGroupExample() {
 	}
 }
 
-func main() {
-	rand.Seed(time.Now().UnixNano())
-	
+func intro() {
 	fmt.Println("╔════════════════════════════════════════════╗")
 	fmt.Println("║   Advanced Goroutine Patterns             ║")
 	fmt.Println("╚════════════════════════════════════════════╝")
-	
-	workerPoolExample()
-	fanInFanOutExample()
-	contextCancellationExample()
-	contextTimeoutExample()
-	rateLimitingExample()
-	semaphoreExample()
-	errorGroupExample()
-	
+}
+
+func outro() {
 	fmt.Println("\n✅ All advanced examples completed!")
 	fmt.Println("\nAdvanced Patterns Summary:")
 	fmt.Println("1. Worker Pool: Fixed number of workers processing jobs")
@@ -1,10 +1,39 @@
-package main
+// Package basic is level 1 of the tutorial: launching goroutines.
+package basic
 
 import (
+	_ "embed"
 	"fmt"
 	"time"
+
+	"golang-concurrency-demo/tutorial/lesson"
 )
 
+// source is shown next to an example's output by the interactive runner
+//
+//go:embed 01_basic_goroutine.go
+var source string
+
+func init() {
+	lesson.Register(lesson.Lesson{
+		Order:       1,
+		Name:        "basic",
+		Title:       "Basic Goroutines",
+		Description: "Launching goroutines, anonymous functions, arguments, the loop variable pitfall",
+		Intro:       intro,
+		Examples: []lesson.Example{
+			{Name: "basicExample", Title: "Basic Goroutine", Run: basicExample},
+			{Name: "multipleGoroutines", Title: "Multiple Goroutines", Run: multipleGoroutines},
+			{Name: "anonymousGoroutine", Title: "Anonymous Function Goroutine", Run: anonymousGoroutine},
+			{Name: "goroutineWithArgs", Title: "Goroutines with Arguments", Run: goroutineWithArgs},
+			{Name: "loopVariablePitfall", Title: "Loop Variable Pitfall (WRONG)", Run: loopVariablePitfall},
+			{Name: "loopVariableFixed", Title: "Loop Variable Fixed (CORRECT)", Run: loopVariableFixed},
+		},
+		Outro:  outro,
+		Source: source,
+	})
+}
+
 // Example 1: The most basic goroutine
 func sayHello() {
 	fmt.Println("Hello from goroutine!")
@@ -87,11 +116,15 @@ func goroutineWithArgs() {
 func loopVariablePitfall() {
 	fmt.Println("\n=== Example 5: Loop Variable Pitfall (WRONG) ===")
 	
-	// WRONG WAY - all goroutines will likely print the same value
-	for i := 0; i < 5; i++ {
+	// WRONG WAY - all goroutines share one variable and will likely print
+	// the same value (since Go 1.22 a variable declared in the for statement
+	// is new on every iteration, but a variable declared outside still isn't)
+	i := 0
+	for i < 5 {
 		go func() {
-			fmt.Printf("Wrong: %d\n", i) // Captures loop variable by reference
+			fmt.Printf("Wrong: %d\n", i) // Captures the shared variable by reference
 		}()
+		i++
 	}
 	
 	time.Sleep(100 * time.Millisecond)
@@ -120,18 +153,13 @@ func loopVariableFixed() {
 	time.Sleep(100 * time.Millisecond)
 }
 
-func main() {
+func intro() {
 	fmt.Println("╔════════════════════════════════════════════╗")
 	fmt.Println("║   Basic Goroutine Examples                ║")
 	fmt.Println("╚════════════════════════════════════════════╝")
-	
-	basicExample()
-	multipleGoroutines()
-	anonymousGoroutine()
-	goroutineWithArgs()
-	loopVariablePitfall()
-	loopVariableFixed()
-	
+}
+
+func outro() {
 	fmt.Println("\n✅ All basic examples completed!")
 	fmt.Println("\nKey Takeaways:")
 	fmt.Println("1. Use 'go' keyword to launch a goroutine")
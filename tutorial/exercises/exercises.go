@@ -1,19 +1,55 @@
-package main
+// Package exercises holds the practice exercises of the tutorial.
+package exercises
 
 import (
+	_ "embed"
 	"fmt"
 	"time"
+
+	"golang-concurrency-demo/tutorial/lesson"
 )
 
+// source is shown next to an example's output by the interactive runner
+//
+//go:embed exercises.go
+var source string
+
 /*
 ╔════════════════════════════════════════════════════════╗
 ║           GOROUTINES PRACTICE EXERCISES                ║
 ╚════════════════════════════════════════════════════════╝
 
-Complete each exercise below. Solutions are in solutions.go
-Run this file: go run exercises.go
+Complete each exercise below. Solutions are in ../solutions/solutions.go
+Run them (from the repository root): go run ./tutorial run exercises
+Check your work: go run ./tutorial grade
 */
 
+func init() {
+	lesson.Register(lesson.Lesson{
+		Order:       4,
+		Name:        "exercises",
+		Title:       "Practice Exercises",
+		Description: "Ten exercises plus a bonus - implement them, then run the grader",
+		Intro:       intro,
+		Examples: []lesson.Example{
+			{Name: "exercise1", Title: "Exercise 1: First Goroutine", Run: exercise1},
+			{Name: "exercise2", Title: "Exercise 2: Multiple Goroutines", Run: exercise2},
+			{Name: "exercise3", Title: "Exercise 3: Using WaitGroup", Run: exercise3},
+			{Name: "exercise4", Title: "Exercise 4: Channel Communication", Run: exercise4},
+			{Name: "exercise5", Title: "Exercise 5: Parallel Sum", Run: exercise5},
+			{Name: "exercise6", Title: "Exercise 6: Worker Pool", Run: exercise6},
+			{Name: "exercise7", Title: "Exercise 7: Select Statement", Run: exercise7},
+			{Name: "exercise8", Title: "Exercise 8: Fix Race Condition", Run: exercise8},
+			{Name: "exercise9", Title: "Exercise 9: Context Cancellation", Run: exercise9},
+			{Name: "exercise10", Title: "Exercise 10: Pipeline", Run: exercise10},
+			{Name: "bonusExercise", Title: "Bonus: Parallel Web Fetcher", Run: bonusExercise},
+		},
+		Outro:  outro,
+		Source: source,
+		Pause:  200 * time.Millisecond,
+	})
+}
+
 // ============================================
 // EXERCISE 1: First Goroutine (Easy)
 // ============================================
@@ -245,50 +281,19 @@ func bonusExercise() {
 	fmt.Println("Bonus exercise not implemented yet!")
 }
 
-func main() {
+func intro() {
 	fmt.Println("╔════════════════════════════════════════════════════════╗")
 	fmt.Println("║         GOROUTINES PRACTICE EXERCISES                  ║")
 	fmt.Println("╚════════════════════════════════════════════════════════╝")
 	fmt.Println("\nInstructions:")
 	fmt.Println("1. Complete each exercise function above")
-	fmt.Println("2. Run: go run exercises.go")
-	fmt.Println("3. Check solutions: solutions.go")
-	fmt.Println("4. Test for race conditions: go run -race exercises.go")
-	
-	// Uncomment exercises as you complete them:
-	
-	exercise1()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise2()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise3()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise4()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise5()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise6()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise7()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise8()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise9()
-	time.Sleep(200 * time.Millisecond)
-	
-	exercise10()
-	time.Sleep(200 * time.Millisecond)
-	
-	bonusExercise()
-	
+	fmt.Println("2. Run: go run ./tutorial run exercises")
+	fmt.Println("3. Check solutions: go run ./tutorial run solutions")
+	fmt.Println("4. Test for race conditions: go run -race ./tutorial run exercises")
+	fmt.Println("5. Grade your answers: go run ./tutorial grade")
+}
+
+func outro() {
 	fmt.Println("\n╔════════════════════════════════════════════════════════╗")
 	fmt.Println("║  Great job practicing! Check solutions.go for answers  ║")
 	fmt.Println("╚════════════════════════════════════════════════════════╝")
@@ -0,0 +1,473 @@
+// Package grader runs the practice exercises one by one and scores them.
+package grader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-concurrency-demo/tutorial/lesson"
+)
+
+/*
+╔════════════════════════════════════════════════════════╗
+║           GOROUTINES EXERCISE GRADER                   ║
+╚════════════════════════════════════════════════════════╝
+
+Runs every exercise on its own (in a separate process, so a hang or a panic
+only fails that one exercise), checks what it printed and how long it took,
+and prints a scorecard with hints.
+
+Run (from the repository root):
+     go run ./tutorial grade
+     go run ./tutorial grade -only exercise8
+     go run ./tutorial grade -solutions     (grade the solutions - should be all green)
+*/
+
+// Options selects what to grade
+type Options struct {
+	Only      string // grade a single exercise, e.g. "exercise8"
+	Solutions bool   // grade the solutions lesson instead of the exercises
+	Race      bool   // run exercises that need it under the race detector
+}
+
+// runResult is what one exercise did when run in isolation
+type runResult struct {
+	output   string        // everything printed
+	body     []string      // printed lines, minus the header and the timing line
+	elapsed  time.Duration // time spent inside the exercise function
+	exitErr  error         // non-nil if the process failed
+	timedOut bool
+}
+
+// text returns the printed lines joined, lower-cased
+func (r runResult) text() string {
+	return strings.ToLower(strings.Join(r.body, "\n"))
+}
+
+// exerciseCheck describes how to grade one exercise
+type exerciseCheck struct {
+	name    string
+	title   string
+	race    bool // run under the race detector
+	timeout time.Duration
+	verify  func(r runResult) error
+	hint    string
+}
+
+var checks = []exerciseCheck{
+	{
+		name: "exercise1", title: "First Goroutine", timeout: 5 * time.Second,
+		verify: func(r runResult) error {
+			if !strings.Contains(r.text(), "hello from goroutine") {
+				return errors.New(`"Hello from goroutine!" was never printed`)
+			}
+			return nil
+		},
+		hint: "Launch the function with `go`, then wait (time.Sleep or a WaitGroup) before returning.",
+	},
+	{
+		name: "exercise2", title: "Multiple Goroutines", timeout: 5 * time.Second,
+		verify: func(r runResult) error {
+			return expectNumbers(r, 1, 5)
+		},
+		hint: "Start 5 goroutines in a loop (pass the ID as an argument) and wait for all of them.",
+	},
+	{
+		name: "exercise3", title: "Using WaitGroup", timeout: 5 * time.Second,
+		verify: func(r runResult) error {
+			for id := 1; id <= 10; id++ {
+				if n := countLinesWith(r, id); n < 2 {
+					return fmt.Errorf("goroutine %d printed %d line(s), want a start and an end message", id, n)
+				}
+			}
+			return nil
+		},
+		hint: "wg.Add(1) before each `go`, defer wg.Done() inside, wg.Wait() at the end.",
+	},
+	{
+		name: "exercise4", title: "Channel Communication", timeout: 5 * time.Second,
+		verify: func(r runResult) error {
+			got := numbersIn(r.body)
+			want := []int{1, 2, 3, 4, 5}
+			if !isSubsequence(want, got) {
+				return fmt.Errorf("expected 1 2 3 4 5 to be received in order, saw %v", got)
+			}
+			return nil
+		},
+		hint: "The sender closes the channel after sending; the receiver uses `for n := range ch`. Wait for the receiver to finish.",
+	},
+	{
+		name: "exercise5", title: "Parallel Sum", timeout: 5 * time.Second,
+		verify: func(r runResult) error {
+			if !containsNumber(r.body, 5050) {
+				return errors.New("the total 5050 was not printed")
+			}
+			return nil
+		},
+		hint: "Each goroutine sends its partial sum on the channel; receive twice and add them up.",
+	},
+	{
+		name: "exercise6", title: "Worker Pool", timeout: 10 * time.Second,
+		verify: func(r runResult) error {
+			return expectNumbers(r, 1, 10)
+		},
+		hint: "Fill a buffered jobs channel, close it, and let 3 workers `range` over it. Close results after wg.Wait().",
+	},
+	{
+		name: "exercise7", title: "Select Statement", timeout: 10 * time.Second,
+		verify: func(r runResult) error {
+			if len(r.body) < 2 {
+				return errors.New("expected a line for each of the two messages")
+			}
+			if r.elapsed >= 2*time.Second {
+				return fmt.Errorf("took %v - the 2s timeout fired instead of the messages arriving", r.elapsed.Round(time.Millisecond))
+			}
+			return nil
+		},
+		hint: "Loop twice around a select with a case for each channel plus `case <-time.After(2*time.Second)`.",
+	},
+	{
+		name: "exercise8", title: "Fix Race Condition", race: true, timeout: 20 * time.Second,
+		verify: func(r runResult) error {
+			if strings.Contains(r.output, "WARNING: DATA RACE") {
+				return errors.New("the race detector found a data race")
+			}
+			// The broken code prints "(should be 10000)" too: only the
+			// counter's own value counts
+			n, ok := counterValue(r.body)
+			if !ok {
+				return errors.New("the counter was never printed")
+			}
+			if n != 10000 {
+				return fmt.Errorf("counter is %d, want exactly 10000", n)
+			}
+			return nil
+		},
+		hint: "Protect counter++ with mu.Lock()/mu.Unlock() and print the counter only after wg.Wait().",
+	},
+	{
+		name: "exercise9", title: "Context Cancellation", timeout: 10 * time.Second,
+		verify: func(r runResult) error {
+			if r.elapsed < 900*time.Millisecond || r.elapsed > 3*time.Second {
+				return fmt.Errorf("took %v, want about 1s (cancel after 1 second)", r.elapsed.Round(time.Millisecond))
+			}
+			if !regexp.MustCompile(`cancel|stop`).MatchString(r.text()) {
+				return errors.New("the goroutine never reported that it stopped")
+			}
+			return nil
+		},
+		hint: "In the goroutine, select on <-ctx.Done() to return; call cancel() after time.Sleep(1 * time.Second).",
+	},
+	{
+		name: "exercise10", title: "Pipeline", timeout: 5 * time.Second,
+		verify: func(r runResult) error {
+			for n := 1; n <= 10; n++ {
+				if !containsNumber(r.body, n*n) {
+					return fmt.Errorf("%d² = %d is missing from the output", n, n*n)
+				}
+			}
+			return nil
+		},
+		hint: "Each stage closes its output channel when its input is exhausted, so the next stage's range loop ends.",
+	},
+	{
+		name: "bonusExercise", title: "Parallel Web Fetcher", timeout: 10 * time.Second,
+		verify: func(r runResult) error {
+			if r.elapsed < 100*time.Millisecond {
+				return fmt.Errorf("finished in %v - did the fetches actually sleep?", r.elapsed.Round(time.Millisecond))
+			}
+			if r.elapsed > 700*time.Millisecond {
+				return fmt.Errorf("took %v; fetching concurrently should take ~500ms, not the sum of all fetches", r.elapsed.Round(time.Millisecond))
+			}
+			return nil
+		},
+		hint: "Start every fetch in its own goroutine and only call wg.Wait() after the loop.",
+	},
+}
+
+// expectNumbers checks that every number from..to appears in the output
+func expectNumbers(r runResult, from, to int) error {
+	var missing []int
+	for n := from; n <= to; n++ {
+		if countLinesWith(r, n) == 0 {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("nothing printed for %v", missing)
+	}
+	return nil
+}
+
+var numberPattern = regexp.MustCompile(`\d+`)
+
+// numbersIn returns every number printed, in order
+func numbersIn(lines []string) []int {
+	var nums []int
+	for _, line := range lines {
+		for _, m := range numberPattern.FindAllString(line, -1) {
+			if n, err := strconv.Atoi(m); err == nil {
+				nums = append(nums, n)
+			}
+		}
+	}
+	return nums
+}
+
+// counterPattern finds the number printed right after "counter"
+var counterPattern = regexp.MustCompile(`(?i)counter\D*?(-?\d+)`)
+
+// counterValue returns the first number printed after "counter"
+func counterValue(lines []string) (int, bool) {
+	for _, line := range lines {
+		if m := counterPattern.FindStringSubmatch(line); m != nil {
+			n, err := strconv.Atoi(m[1])
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+func containsNumber(lines []string, want int) bool {
+	for _, n := range numbersIn(lines) {
+		if n == want {
+			return true
+		}
+	}
+	return false
+}
+
+// countLinesWith counts the printed lines that mention the number n
+func countLinesWith(r runResult, n int) int {
+	count := 0
+	for _, line := range r.body {
+		if containsNumber([]string{line}, n) {
+			count++
+		}
+	}
+	return count
+}
+
+// isSubsequence reports whether want appears in got in order
+func isSubsequence(want, got []int) bool {
+	i := 0
+	for _, n := range got {
+		if i < len(want) && n == want[i] {
+			i++
+		}
+	}
+	return i == len(want)
+}
+
+var finishedPattern = regexp.MustCompile(`^--- (\S+) finished in (\S+)$`)
+
+// runExercise runs one example ("lesson/example") in its own process, through
+// the tutorial launcher, and collects what it did
+func runExercise(binary, target string, c exerciseCheck) runResult {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	// The grader's own timeout (and message) applies, not the launcher's watchdog
+	cmd := exec.CommandContext(ctx, binary, "run", "-timing", "-watchdog=0", target)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	r := runResult{output: out.String(), exitErr: err, timedOut: ctx.Err() != nil}
+	for _, line := range strings.Split(strings.TrimSpace(r.output), "\n") {
+		if m := finishedPattern.FindStringSubmatch(line); m != nil {
+			r.elapsed, _ = time.ParseDuration(m[2])
+			continue
+		}
+		if strings.HasPrefix(line, "=== ") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		r.body = append(r.body, line)
+	}
+	return r
+}
+
+// errNoCgo means the race detector cannot be built because cgo is off
+var errNoCgo = errors.New("the race detector needs cgo")
+
+// buildRace compiles the tutorial launcher with the race detector into dir.
+// It builds in the module the grader itself was built from, so the grader
+// can be started from any directory.
+func buildRace(dir string) (string, error) {
+	binary := filepath.Join(dir, "tutorial-race")
+	cmd := exec.Command("go", "build", "-race", "-o", binary, "golang-concurrency-demo/tutorial")
+	cmd.Dir = moduleDir()
+	out, err := cmd.CombinedOutput()
+	if bytes.Contains(out, []byte("-race requires cgo")) {
+		return "", errNoCgo
+	}
+	if err != nil {
+		return "", fmt.Errorf("go build -race: %v\n%s", err, bytes.TrimSpace(out))
+	}
+	return binary, nil
+}
+
+// moduleDir is the root of the module this file was compiled in, or "" (the
+// current directory) if that source tree is not there any more
+func moduleDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return ""
+	}
+	root := filepath.Join(filepath.Dir(file), "..", "..")
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		return ""
+	}
+	return root
+}
+
+// targets maps each exercise name to the "lesson/example" that is graded for
+// it. Solutions are matched to exercises by position, since solution N
+// solves exercise N.
+func targets(solutions bool) (map[string]string, error) {
+	exercises, ok := lesson.Find("exercises")
+	if !ok {
+		return nil, errors.New("the exercises lesson is not registered")
+	}
+	m := make(map[string]string, len(exercises.Examples))
+	if !solutions {
+		for _, ex := range exercises.Examples {
+			m[ex.Name] = "exercises/" + ex.Name
+		}
+		return m, nil
+	}
+
+	sol, ok := lesson.Find("solutions")
+	if !ok {
+		return nil, errors.New("the solutions lesson is not registered")
+	}
+	if len(sol.Examples) != len(exercises.Examples) {
+		return nil, fmt.Errorf("%d solutions for %d exercises", len(sol.Examples), len(exercises.Examples))
+	}
+	for i, ex := range exercises.Examples {
+		m[ex.Name] = "solutions/" + sol.Examples[i].Name
+	}
+	return m, nil
+}
+
+// Run grades the exercises (or their solutions) and prints a scorecard.
+// Each exercise runs in a child process of the running launcher binary.
+func Run(opts Options) error {
+	graded := "the exercises"
+	if opts.Solutions {
+		graded = "the solutions"
+	}
+
+	fmt.Println("╔════════════════════════════════════════════════════════╗")
+	fmt.Println("║           GOROUTINES EXERCISE GRADER                   ║")
+	fmt.Println("╚════════════════════════════════════════════════════════╝")
+	fmt.Printf("Grading %s (each exercise runs in its own process)\n\n", graded)
+
+	if opts.Only != "" {
+		found := false
+		for _, c := range checks {
+			found = found || c.name == opts.Only
+		}
+		if !found {
+			return fmt.Errorf("unknown exercise %q", opts.Only)
+		}
+	}
+
+	exerciseTargets, err := targets(opts.Solutions)
+	if err != nil {
+		return err
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	raceBinary := ""
+	if opts.Race {
+		dir, err := os.MkdirTemp("", "grader")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		raceBinary, err = buildRace(dir)
+		switch {
+		case errors.Is(err, errNoCgo):
+			fmt.Println("⚠️  The race detector is not available here (it needs cgo); race checks are skipped.")
+			fmt.Println()
+		case err != nil:
+			fmt.Println("⚠️  Could not build the exercises with the race detector; race checks are skipped:")
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Println("   " + line)
+			}
+			fmt.Println()
+		}
+	}
+
+	passed, total := 0, 0
+	for _, c := range checks {
+		if opts.Only != "" && c.name != opts.Only {
+			continue
+		}
+		total++
+
+		bin := binary
+		if c.race && raceBinary != "" {
+			bin = raceBinary
+		}
+		r := runExercise(bin, exerciseTargets[c.name], c)
+
+		status, detail := "✅ PASS", ""
+		switch {
+		case strings.Contains(r.output, "not implemented yet"):
+			status, detail = "⬜ TODO", "still prints \"not implemented yet\""
+		case r.timedOut:
+			status, detail = "❌ FAIL", fmt.Sprintf("did not finish within %v (deadlock? a channel nobody closes?)", c.timeout)
+		case r.exitErr != nil && !strings.Contains(r.output, "WARNING: DATA RACE"):
+			status, detail = "❌ FAIL", "crashed: "+firstErrorLine(r.output, r.exitErr)
+		default:
+			if err := c.verify(r); err != nil {
+				status, detail = "❌ FAIL", err.Error()
+			}
+		}
+
+		fmt.Printf("%s  %-14s %-24s", status, c.name, c.title)
+		if r.elapsed > 0 && !strings.HasPrefix(status, "⬜") {
+			fmt.Printf(" %8v", r.elapsed.Round(time.Millisecond))
+		}
+		fmt.Println()
+		if status == "✅ PASS" {
+			passed++
+			continue
+		}
+		fmt.Printf("          %s\n", detail)
+		fmt.Printf("          💡 %s\n", c.hint)
+	}
+
+	fmt.Printf("\nScore: %d/%d\n", passed, total)
+	if passed == total {
+		fmt.Println("🎉 All exercises pass!")
+	}
+	return nil
+}
+
+// firstErrorLine finds the most useful line of a crashed exercise's output
+func firstErrorLine(output string, err error) string {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "panic:") || strings.HasPrefix(line, "fatal error:") {
+			return line
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-concurrency-demo/tutorial/grader"
+	"golang-concurrency-demo/tutorial/lesson"
+)
+
+// ============================================================================
+// INTERACTIVE RUNNER: Pick a lesson, step through its examples one at a time
+// ============================================================================
+
+const divider = "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"
+
+// runner is the state of one interactive session
+type runner struct {
+	in          *bufio.Reader
+	showSource  bool
+	stallWindow time.Duration // watchdog window while an example runs
+}
+
+// prompt prints msg and reads one line. ok is false once input runs out.
+func (r *runner) prompt(msg string) (answer string, ok bool) {
+	fmt.Print(msg)
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// printSource prints an example's function with line numbers
+func printSource(l lesson.Lesson, ex lesson.Example) {
+	src, err := l.ExampleSource(ex.Name)
+	if err != nil {
+		fmt.Printf("⚠️  Source not available: %v\n", err)
+		return
+	}
+	fmt.Printf("📄 Source (%s/%s)\n", l.Name, ex.Name)
+	for i, line := range strings.Split(src, "\n") {
+		fmt.Printf("%4d │ %s\n", i+1, line)
+	}
+}
+
+// runExample shows the example's source (if enabled), then runs it
+func (r *runner) runExample(l lesson.Lesson, ex lesson.Example) {
+	fmt.Println()
+	fmt.Println(divider)
+	if r.showSource {
+		printSource(l, ex)
+		fmt.Println(divider)
+	}
+	fmt.Printf("▶️  Output of %s\n", ex.Name)
+	watched(r.stallWindow, ex.Run)
+	fmt.Println(divider)
+}
+
+// stepThrough runs every example of a lesson, waiting for Enter in between
+func (r *runner) stepThrough(l lesson.Lesson) {
+	if l.Intro != nil {
+		l.Intro()
+	}
+	for i, ex := range l.Examples {
+		if i > 0 {
+			answer, ok := r.prompt(fmt.Sprintf("⏎  Enter for step %d/%d (%s), q to stop: ", i+1, len(l.Examples), ex.Title))
+			if !ok || strings.EqualFold(answer, "q") {
+				return
+			}
+		}
+		r.runExample(l, ex)
+	}
+	if l.Outro != nil {
+		l.Outro()
+	}
+}
+
+// lessonMenu lets the user run examples of one lesson until they go back.
+// It returns false once input runs out.
+func (r *runner) lessonMenu(l lesson.Lesson) bool {
+	for {
+		fmt.Printf("\n📘 %s\n   %s\n\n", l.Title, l.Description)
+		for i, ex := range l.Examples {
+			fmt.Printf("  %2d. %-36s (%s)\n", i+1, ex.Title, ex.Name)
+		}
+		fmt.Println()
+		fmt.Println("   a. Step through all examples")
+		fmt.Printf("   s. Show source next to output (now: %v)\n", onOff(r.showSource))
+		fmt.Println("   b. Back to lessons")
+		fmt.Println()
+
+		answer, ok := r.prompt("Select an example (number or name): ")
+		if !ok {
+			return false
+		}
+		switch strings.ToLower(answer) {
+		case "b", "":
+			return true
+		case "a":
+			r.stepThrough(l)
+			continue
+		case "s":
+			r.showSource = !r.showSource
+			continue
+		}
+
+		ex, found := pick(l.Examples, answer, func(ex lesson.Example) string { return ex.Name })
+		if !found {
+			fmt.Printf("❌ No example %q\n", answer)
+			continue
+		}
+		r.runExample(l, ex)
+		if _, ok := r.prompt("⏎  Press Enter to continue..."); !ok {
+			return false
+		}
+	}
+}
+
+// mainMenu is the top level of the interactive session
+func (r *runner) mainMenu() {
+	for {
+		fmt.Println()
+		fmt.Println("╔════════════════════════════════════════════════════════╗")
+		fmt.Println("║         GOROUTINES TUTORIAL - Lesson Runner            ║")
+		fmt.Println("╚════════════════════════════════════════════════════════╝")
+		lessons := lesson.All()
+		for i, l := range lessons {
+			fmt.Printf("  %d. %-22s %s\n", i+1, l.Title, l.Description)
+		}
+		fmt.Println()
+		fmt.Println("  g. Grade your exercises")
+		fmt.Println("  q. Quit")
+		fmt.Println()
+
+		answer, ok := r.prompt("Select a lesson (number or name): ")
+		if !ok {
+			return
+		}
+		switch strings.ToLower(answer) {
+		case "q":
+			fmt.Println("Goodbye! Happy learning! 🚀")
+			return
+		case "g":
+			if err := grader.Run(grader.Options{Race: true}); err != nil {
+				fmt.Println("❌", err)
+			}
+			continue
+		case "":
+			continue
+		}
+
+		l, found := pick(lessons, answer, func(l lesson.Lesson) string { return l.Name })
+		if !found {
+			fmt.Printf("❌ No lesson %q\n", answer)
+			continue
+		}
+		if !r.lessonMenu(l) {
+			return
+		}
+	}
+}
+
+// pick finds an item by its 1-based number or by name
+func pick[T any](items []T, answer string, nameOf func(T) string) (T, bool) {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(items) {
+		return items[n-1], true
+	}
+	for _, item := range items {
+		if strings.EqualFold(nameOf(item), answer) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// interactiveCommand implements `interactive [-source=false] [-watchdog d]`
+func interactiveCommand(args []string) error {
+	fs := flag.NewFlagSet("interactive", flag.ExitOnError)
+	showSource := fs.Bool("source", true, "show each example's source next to its output")
+	stallWindow := fs.Duration("watchdog", defaultStallWindow, "exit with a diagnostic when an example prints nothing for this long (0 = off)")
+	fs.Parse(args)
+
+	r := &runner{in: bufio.NewReader(os.Stdin), showSource: *showSource, stallWindow: *stallWindow}
+	r.mainMenu()
+	return nil
+}
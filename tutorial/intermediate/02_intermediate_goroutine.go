@@ -1,11 +1,41 @@
-package main
+// Package intermediate is level 2 of the tutorial: WaitGroups and channels.
+package intermediate
 
 import (
+	_ "embed"
 	"fmt"
 	"sync"
 	"time"
+
+	"golang-concurrency-demo/tutorial/lesson"
 )
 
+// source is shown next to an example's output by the interactive runner
+//
+//go:embed 02_intermediate_goroutine.go
+var source string
+
+func init() {
+	lesson.Register(lesson.Lesson{
+		Order:       2,
+		Name:        "intermediate",
+		Title:       "Intermediate Patterns",
+		Description: "WaitGroups, channels, buffering, channel direction, select, non-blocking operations",
+		Intro:       intro,
+		Examples: []lesson.Example{
+			{Name: "waitGroupExample", Title: "WaitGroup", Run: waitGroupExample},
+			{Name: "basicChannel", Title: "Basic Channel", Run: basicChannel},
+			{Name: "bufferedChannel", Title: "Buffered Channel", Run: bufferedChannel},
+			{Name: "channelDirection", Title: "Channel Direction", Run: channelDirection},
+			{Name: "pipelineExample", Title: "Pipeline with WaitGroup", Run: pipelineExample},
+			{Name: "selectExample", Title: "Select Statement", Run: selectExample},
+			{Name: "nonBlockingChannel", Title: "Non-blocking Channel Operations", Run: nonBlockingChannel},
+		},
+		Outro:  outro,
+		Source: source,
+	})
+}
+
 // Example 1: Using WaitGroup to wait for goroutines
 func worker(id int, wg *sync.WaitGroup) {
 	defer wg.Done() // Decrement counter when goroutine completes
@@ -210,19 +240,13 @@ func nonBlockingChannel() {
 	}
 }
 
-func main() {
+func intro() {
 	fmt.Println("╔════════════════════════════════════════════╗")
 	fmt.Println("║   Intermediate Goroutine Examples         ║")
 	fmt.Println("╚════════════════════════════════════════════╝")
-	
-	waitGroupExample()
-	basicChannel()
-	bufferedChannel()
-	channelDirection()
-	pipelineExample()
-	selectExample()
-	nonBlockingChannel()
-	
+}
+
+func outro() {
 	fmt.Println("\n✅ All intermediate examples completed!")
 	fmt.Println("\nKey Concepts:")
 	fmt.Println("1. WaitGroup: Wait for multiple goroutines to complete")
@@ -0,0 +1,163 @@
+// Package lesson is the registry of tutorial lessons.
+//
+// Every lesson package (basic, intermediate, advanced, exercises, solutions)
+// registers itself from init, so the launcher in the tutorial directory can
+// list and run any lesson or any single example in it.
+package lesson
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Example is one runnable example (or exercise) inside a lesson
+type Example struct {
+	Name  string // the function's name, e.g. "contextTimeoutExample"
+	Title string
+	Run   func()
+}
+
+// Lesson is one level of the tutorial
+type Lesson struct {
+	Order       int    // position in the learning path
+	Name        string // short name used on the command line, e.g. "advanced"
+	Title       string
+	Description string
+
+	Intro    func() // banner printed before the examples (optional)
+	Examples []Example
+	Outro    func()        // key takeaways printed after the examples (optional)
+	Pause    time.Duration // pause between examples when the whole lesson runs
+
+	Source string // the lesson's Go source (embedded), for showing examples
+}
+
+var lessons = make(map[string]Lesson)
+
+// Register adds a lesson to the registry. It panics on a duplicate name,
+// since that can only be a programming mistake.
+func Register(l Lesson) {
+	if _, dup := lessons[l.Name]; dup {
+		panic(fmt.Sprintf("lesson %q registered twice", l.Name))
+	}
+	lessons[l.Name] = l
+}
+
+// All returns every registered lesson in learning-path order
+func All() []Lesson {
+	all := make([]Lesson, 0, len(lessons))
+	for _, l := range lessons {
+		all = append(all, l)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Order < all[j].Order })
+	return all
+}
+
+// Find looks a lesson up by name
+func Find(name string) (Lesson, bool) {
+	l, ok := lessons[name]
+	return l, ok
+}
+
+// Resolve looks up "lesson" or "lesson/example". The example is nil when
+// only a lesson was named.
+func Resolve(target string) (Lesson, *Example, error) {
+	name, exampleName, hasExample := strings.Cut(target, "/")
+	l, ok := Find(name)
+	if !ok {
+		return Lesson{}, nil, fmt.Errorf("unknown lesson %q", name)
+	}
+	if !hasExample {
+		return l, nil, nil
+	}
+	ex, ok := l.Example(exampleName)
+	if !ok {
+		return Lesson{}, nil, fmt.Errorf("lesson %q has no example %q", name, exampleName)
+	}
+	return l, &ex, nil
+}
+
+// Example looks up an example of the lesson by name
+func (l Lesson) Example(name string) (Example, bool) {
+	for _, ex := range l.Examples {
+		if ex.Name == name {
+			return ex, true
+		}
+	}
+	return Example{}, false
+}
+
+// Run runs the whole lesson: intro, every example, outro
+func (l Lesson) Run() {
+	if l.Intro != nil {
+		l.Intro()
+	}
+	for i, ex := range l.Examples {
+		if i > 0 {
+			time.Sleep(l.Pause)
+		}
+		ex.Run()
+	}
+	if l.Outro != nil {
+		l.Outro()
+	}
+}
+
+// ExampleSource returns the source of an example's function, with its doc
+// comment, cut out of the lesson's embedded source. Helper functions of the
+// lesson that the example calls (directly or through other helpers) follow it.
+func (l Lesson) ExampleSource(name string) (string, error) {
+	if l.Source == "" {
+		return "", fmt.Errorf("lesson %q has no embedded source", l.Name)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, l.Name+".go", l.Source, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			funcs[fn.Name.Name] = fn
+		}
+	}
+	if _, ok := funcs[name]; !ok {
+		return "", fmt.Errorf("lesson %q has no function %s", l.Name, name)
+	}
+
+	// The example first, then every helper in the order it is first called
+	order := []string{name}
+	seen := map[string]bool{name: true}
+	for i := 0; i < len(order); i++ {
+		ast.Inspect(funcs[order[i]].Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if id, ok := call.Fun.(*ast.Ident); ok && funcs[id.Name] != nil && !seen[id.Name] {
+				seen[id.Name] = true
+				order = append(order, id.Name)
+			}
+			return true
+		})
+	}
+
+	parts := make([]string, len(order))
+	for i, fnName := range order {
+		fn := funcs[fnName]
+		start := fn.Pos()
+		if fn.Doc != nil {
+			start = fn.Doc.Pos()
+		}
+		src := []byte(l.Source)[fset.Position(start).Offset:fset.Position(fn.End()).Offset]
+		parts[i] = string(bytes.TrimRight(src, "\n"))
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
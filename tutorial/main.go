@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"golang-concurrency-demo/leakcheck"
+	"golang-concurrency-demo/timeline"
+	"golang-concurrency-demo/tutorial/grader"
+	"golang-concurrency-demo/tutorial/lesson"
+	"golang-concurrency-demo/watchdog"
+
+	// Lessons register themselves with the lesson package
+	_ "golang-concurrency-demo/tutorial/advanced"
+	_ "golang-concurrency-demo/tutorial/basic"
+	_ "golang-concurrency-demo/tutorial/exercises"
+	_ "golang-concurrency-demo/tutorial/intermediate"
+	_ "golang-concurrency-demo/tutorial/solutions"
+)
+
+/*
+╔════════════════════════════════════════════════════════╗
+║           GOROUTINES TUTORIAL LAUNCHER                 ║
+╚════════════════════════════════════════════════════════╝
+
+One entry point for every lesson, run from the repository root:
+
+     go run ./tutorial                          (list lessons)
+     go run ./tutorial interactive              (menu, step by step, with source)
+     go run ./tutorial run advanced             (a whole lesson)
+     go run ./tutorial run advanced/semaphoreExample
+     go run ./tutorial grade                    (score your exercises)
+*/
+
+const usage = `usage:
+   go run ./tutorial [list]
+   go run ./tutorial interactive [-source=false] [-watchdog 10s]
+   go run ./tutorial run [-timing] [-source] [-check-leaks] [-watchdog 10s] [-timeline out.html] <lesson>[/<example>]
+   go run ./tutorial grade [-only exercise] [-solutions] [-race=false]`
+
+// listLessons prints every lesson and its examples
+func listLessons() {
+	fmt.Println("Lessons (go run ./tutorial run <lesson>[/<example>]):")
+	for _, l := range lesson.All() {
+		fmt.Printf("\n   %d. %-13s %s\n", l.Order, l.Name, l.Title)
+		fmt.Printf("      %s\n", l.Description)
+		for _, ex := range l.Examples {
+			fmt.Printf("         %s/%s\n", l.Name, ex.Name)
+		}
+	}
+}
+
+// defaultStallWindow is how long an example may go without printing anything
+// before the watchdog calls it stuck
+const defaultStallWindow = 10 * time.Second
+
+// watched runs fn under a watchdog that counts output as progress, so an
+// example that hangs (a channel nobody closes, a missing wg.Done) exits with
+// a diagnostic instead of hanging. A window of 0 turns the watchdog off.
+func watched(window time.Duration, fn func()) {
+	if window <= 0 {
+		fn()
+		return
+	}
+	w := watchdog.New(window)
+	restoreStdout := w.WatchStdout()
+	w.Start()
+	fn()
+	w.Stop()
+	restoreStdout()
+}
+
+// runCommand implements `run [-timing] [-source] [-check-leaks] [-watchdog d] <lesson>[/<example>]`
+func runCommand(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	timing := fs.Bool("timing", false, "print how long the lesson or example took (used by the grader)")
+	showSource := fs.Bool("source", false, "print the example's source before running it")
+	checkLeaks := fs.Bool("check-leaks", false, "report goroutines left running afterwards, and exit with status 1 if there are any")
+	stallWindow := fs.Duration("watchdog", defaultStallWindow, "exit with a diagnostic after this long without output (0 = off)")
+	timelinePath := fs.String("timeline", "", "write an HTML Gantt chart of the example's goroutines to this file")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("want exactly one lesson or lesson/example\n%s", usage)
+	}
+
+	target := fs.Arg(0)
+	l, ex, err := lesson.Resolve(target)
+	if err != nil {
+		return err
+	}
+
+	if *showSource && ex != nil {
+		printSource(l, *ex)
+		fmt.Println(divider)
+	}
+
+	var snapshot leakcheck.Snapshot
+	if *checkLeaks {
+		snapshot = leakcheck.Take()
+	}
+
+	var rec *timeline.Recorder
+	stopRecording := func() {}
+	if *timelinePath != "" && len(l.Examples) > 0 {
+		rec = timeline.New(target)
+		stopRecording = recordTimeline(rec, packageOf(l.Examples[0].Run))
+	}
+
+	start := time.Now()
+	watched(*stallWindow, func() {
+		if ex != nil {
+			ex.Run()
+		} else {
+			l.Run()
+		}
+	})
+	stopRecording()
+	if *timing {
+		fmt.Printf("--- %s finished in %v\n", target, time.Since(start))
+	}
+
+	if rec != nil {
+		if err := rec.Save(*timelinePath); err != nil {
+			return err
+		}
+		fmt.Printf("\n📊 Timeline written to %s (open it in a browser)\n", *timelinePath)
+	}
+
+	if *checkLeaks {
+		if leaks := snapshot.Leaked(); len(leaks) > 0 {
+			fmt.Print("\n" + leakcheck.Report(leaks))
+			os.Exit(1)
+		}
+		fmt.Println("\n✅ No goroutines leaked")
+	}
+	return nil
+}
+
+// gradeCommand implements `grade [-only exercise] [-solutions] [-race]`
+func gradeCommand(args []string) error {
+	fs := flag.NewFlagSet("grade", flag.ExitOnError)
+	var opts grader.Options
+	fs.StringVar(&opts.Only, "only", "", "grade a single exercise, e.g. -only exercise8")
+	fs.BoolVar(&opts.Solutions, "solutions", false, "grade the solutions instead of the exercises")
+	fs.BoolVar(&opts.Race, "race", true, "run exercises that need it under the race detector")
+	fs.Parse(args)
+	return grader.Run(opts)
+}
+
+func main() {
+	// Seed random number generator
+	rand.Seed(time.Now().UnixNano())
+
+	args := os.Args[1:]
+	command := "list"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "list":
+		listLessons()
+	case "interactive":
+		err = interactiveCommand(args)
+	case "run":
+		err = runCommand(args)
+	case "grade":
+		err = gradeCommand(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Println(usage)
+	default:
+		err = fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,18 @@
+#!/bin/bash
+
+# Goroutines Tutorial Runner
+# Starts the interactive lesson runner (tutorial/interactive.go): pick a
+# lesson, run its examples one at a time and see each one's source next to
+# its output. Any arguments are passed on, e.g. ./run.sh -source=false
+
+# Check if Go is installed
+if ! command -v go &> /dev/null; then
+    echo "❌ Go is not installed!"
+    echo "📦 Install with: sudo apt install golang-go"
+    echo "   Or: sudo snap install go --classic"
+    exit 1
+fi
+
+# The lessons are packages of the project's module: run them from its root
+cd "$(dirname "$0")/.." || exit 1
+exec go run ./tutorial interactive "$@"
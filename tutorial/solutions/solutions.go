@@ -1,22 +1,57 @@
-package main
+// Package solutions holds the solutions to the practice exercises.
+package solutions
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"math/rand"
 	"sync"
 	"time"
+
+	"golang-concurrency-demo/tutorial/lesson"
 )
 
+// source is shown next to an example's output by the interactive runner
+//
+//go:embed solutions.go
+var source string
+
 /*
 ╔════════════════════════════════════════════════════════╗
 ║         GOROUTINES EXERCISE SOLUTIONS                  ║
 ╚════════════════════════════════════════════════════════╝
 
-Solutions to exercises.go
+Solutions to ../exercises/exercises.go
 Study these after attempting the exercises yourself!
 */
 
+func init() {
+	lesson.Register(lesson.Lesson{
+		Order:       5,
+		Name:        "solutions",
+		Title:       "Exercise Solutions",
+		Description: "Worked solutions to the practice exercises",
+		Intro:       intro,
+		Examples: []lesson.Example{
+			{Name: "solution1", Title: "Solution 1: First Goroutine", Run: solution1},
+			{Name: "solution2", Title: "Solution 2: Multiple Goroutines", Run: solution2},
+			{Name: "solution3", Title: "Solution 3: Using WaitGroup", Run: solution3},
+			{Name: "solution4", Title: "Solution 4: Channel Communication", Run: solution4},
+			{Name: "solution5", Title: "Solution 5: Parallel Sum", Run: solution5},
+			{Name: "solution6", Title: "Solution 6: Worker Pool", Run: solution6},
+			{Name: "solution7", Title: "Solution 7: Select Statement", Run: solution7},
+			{Name: "solution8", Title: "Solution 8: Fix Race Condition", Run: solution8},
+			{Name: "solution9", Title: "Solution 9: Context Cancellation", Run: solution9},
+			{Name: "solution10", Title: "Solution 10: Pipeline", Run: solution10},
+			{Name: "bonusSolution", Title: "Bonus: Parallel Web Fetcher", Run: bonusSolution},
+		},
+		Outro:  outro,
+		Source: source,
+		Pause:  200 * time.Millisecond,
+	})
+}
+
 // ============================================
 // SOLUTION 1: First Goroutine
 // ============================================
@@ -405,48 +440,16 @@ func bonusSolution() {
 	fmt.Println("✅ Parallel fetching completed!")
 }
 
-func main() {
-	// Seed random number generator
-	rand.Seed(time.Now().UnixNano())
-	
+func intro() {
 	fmt.Println("╔════════════════════════════════════════════════════════╗")
 	fmt.Println("║         GOROUTINES EXERCISE SOLUTIONS                  ║")
 	fmt.Println("╚════════════════════════════════════════════════════════╝")
-	fmt.Println("\nThese are the solutions to exercises.go")
-	fmt.Println("Study them after attempting the exercises yourself!\n")
-	
-	solution1()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution2()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution3()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution4()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution5()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution6()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution7()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution8()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution9()
-	time.Sleep(200 * time.Millisecond)
-	
-	solution10()
-	time.Sleep(200 * time.Millisecond)
-	
-	bonusSolution()
-	
+	fmt.Println("\nThese are the solutions to the exercises")
+	fmt.Println("Study them after attempting the exercises yourself!")
+	fmt.Println()
+}
+
+func outro() {
 	fmt.Println("\n╔════════════════════════════════════════════════════════╗")
 	fmt.Println("║  All solutions demonstrated! Keep practicing! 🚀       ║")
 	fmt.Println("╚════════════════════════════════════════════════════════╝")
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang-concurrency-demo/internal/stackdump"
+	"golang-concurrency-demo/timeline"
+)
+
+// sampleEvery is how often -timeline looks at the example's goroutines
+const sampleEvery = 5 * time.Millisecond
+
+// packageOf returns the package prefix of fn's name, e.g.
+// "golang-concurrency-demo/tutorial/advanced."
+func packageOf(fn func()) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	slash := strings.LastIndex(name, "/")
+	return name[:slash+strings.Index(name[slash:], ".")+1]
+}
+
+// activity says what a goroutine is doing, as a label and a kind of span
+func activity(g stackdump.Goroutine) (string, timeline.Kind) {
+	switch {
+	case strings.HasPrefix(g.State, "chan receive"):
+		return "waiting to receive", timeline.KindWait
+	case strings.HasPrefix(g.State, "chan send"):
+		return "waiting to send", timeline.KindWait
+	case strings.HasPrefix(g.State, "select"):
+		return "waiting in select", timeline.KindWait
+	case g.HasFunc("sync.(*WaitGroup).Wait"):
+		return "waiting for the WaitGroup", timeline.KindWait
+	case g.HasFunc("sync.(*Mutex).Lock"), g.HasFunc("sync.(*RWMutex)."):
+		return "waiting for a lock", timeline.KindWait
+	}
+	return "working", timeline.KindWork // examples "work" by sleeping
+}
+
+// goroutineLane is a goroutine's lane and what it has been doing since when
+type goroutineLane struct {
+	name  string
+	label string
+	kind  timeline.Kind
+	since time.Time
+}
+
+// recordTimeline charts the goroutines running code of pkg: it samples every
+// goroutine's stack and records a span each time one of them changes from
+// working to waiting or back. Stacks are sampled, not instrumented, so the
+// examples stay as they are taught. Call the returned func to stop.
+func recordTimeline(rec *timeline.Recorder, pkg string) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lanes := make(map[int]*goroutineLane)
+
+	// end closes the spans of the lanes not in seen
+	end := func(seen map[int]bool, now time.Time) {
+		for id, l := range lanes {
+			if !seen[id] {
+				rec.Add(l.name, l.label, l.kind, l.since, now)
+				delete(lanes, id)
+			}
+		}
+	}
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(sampleEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				end(nil, time.Now())
+				return
+			case <-ticker.C:
+			}
+
+			now := time.Now()
+			seen := make(map[int]bool)
+			for _, g := range stackdump.Parse(stackdump.Capture()) {
+				// The outermost frame of the example's code names the lane
+				var entry *stackdump.Frame
+				for i := range g.Frames {
+					if strings.HasPrefix(g.Frames[i].Func, pkg) {
+						entry = &g.Frames[i]
+					}
+				}
+				if entry == nil {
+					continue
+				}
+				seen[g.ID] = true
+
+				label, kind := activity(g)
+				l, ok := lanes[g.ID]
+				switch {
+				case !ok:
+					name := fmt.Sprintf("%s (goroutine %d)", entry.FuncName(), g.ID)
+					lanes[g.ID] = &goroutineLane{name: name, label: label, kind: kind, since: now}
+				case l.label != label:
+					rec.Add(l.name, l.label, l.kind, l.since, now)
+					l.label, l.kind, l.since = label, kind, now
+				}
+			}
+			end(seen, now)
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
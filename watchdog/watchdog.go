@@ -0,0 +1,447 @@
+// Package watchdog notices when a concurrent program stops making progress
+// and explains where it is stuck.
+//
+// Go's runtime only reports "all goroutines are asleep - deadlock!" when
+// nothing at all can run; a single live timer or ticker is enough to keep it
+// quiet, and the program just hangs. A Watchdog instead expects a Beat every
+// so often. When none arrives for a whole window it prints which stages made
+// progress and when, how full the registered channels are, what every
+// blocked goroutine is waiting for, a guess at which stage is stuck and why,
+// and every goroutine's stack - then exits.
+//
+//	w := watchdog.New(5 * time.Second)
+//	w.Channel("squares", squares)
+//	w.Start()
+//	defer w.Stop()
+//	...
+//	for n := range in {
+//		squares <- n * n
+//		w.Beat("square")
+//	}
+//
+// A nil *Watchdog is valid and does nothing, so code can Beat
+// unconditionally and only create a Watchdog when it is wanted.
+package watchdog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-concurrency-demo/internal/stackdump"
+)
+
+// stage is the progress reported under one name
+type stage struct {
+	name  string
+	beats int
+	last  time.Time
+}
+
+// channel is a channel whose queue is shown in the report
+type channel struct {
+	name string
+	ch   reflect.Value
+}
+
+// Watchdog detects a stall: no Beat for a whole window
+type Watchdog struct {
+	// OnStall receives the report when a stall is detected. The default
+	// prints it to standard error and exits with status 2.
+	OnStall func(report string)
+
+	window time.Duration
+	stderr io.Writer // captured before WatchStdout can swap os.Stdout
+
+	mu       sync.Mutex
+	last     time.Time
+	stages   map[string]*stage
+	order    []string // stage names in the order they first made progress
+	channels []channel
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a watchdog that calls a stall after window without progress
+func New(window time.Duration) *Watchdog {
+	return &Watchdog{
+		window: window,
+		stderr: os.Stderr,
+		stages: make(map[string]*stage),
+	}
+}
+
+// Start begins watching. The window starts now.
+func (w *Watchdog) Start() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.last = time.Now()
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	w.mu.Unlock()
+
+	go w.watch(w.stop, w.done)
+}
+
+// Stop stops watching. It is safe to Start again afterwards.
+func (w *Watchdog) Stop() {
+	if w == nil || w.stop == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+	w.stop = nil
+}
+
+// Beat records progress made by a stage. Name stages after the function that
+// runs them, so the report can tell which ones are still running.
+func (w *Watchdog) Beat(stageName string) {
+	if w == nil {
+		return
+	}
+	now := time.Now()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.last = now
+	s, ok := w.stages[stageName]
+	if !ok {
+		s = &stage{name: stageName}
+		w.stages[stageName] = s
+		w.order = append(w.order, stageName)
+	}
+	s.beats++
+	s.last = now
+}
+
+// Channel registers a channel (of any element type or direction) whose
+// length and capacity should be shown in the report. Registering a name
+// again (a stage that runs in cycles, say) replaces the earlier channel.
+func (w *Watchdog) Channel(name string, ch any) {
+	if w == nil {
+		return
+	}
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan {
+		panic(fmt.Sprintf("watchdog: Channel(%q) given a %T, not a channel", name, ch))
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range w.channels {
+		if w.channels[i].name == name {
+			w.channels[i].ch = v
+			return
+		}
+	}
+	w.channels = append(w.channels, channel{name: name, ch: v})
+}
+
+// StdoutStage is the stage that WatchStdout reports progress under
+const StdoutStage = "stdout"
+
+// progressWriter passes writes through and counts each one as progress
+type progressWriter struct {
+	w   *Watchdog
+	out io.Writer
+}
+
+func (p progressWriter) Write(b []byte) (int, error) {
+	p.w.Beat(StdoutStage)
+	return p.out.Write(b)
+}
+
+// WatchStdout counts every write to standard output as progress, for
+// programs that cannot Beat themselves. It swaps os.Stdout for a pipe; the
+// returned function puts the real one back once everything was copied.
+func (w *Watchdog) WatchStdout() (restore func()) {
+	if w == nil {
+		return func() {}
+	}
+	r, pw, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+
+	stdout := os.Stdout
+	os.Stdout = pw
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(progressWriter{w: w, out: stdout}, r)
+		r.Close()
+		close(copied)
+	}()
+
+	return func() {
+		os.Stdout = stdout
+		pw.Close()
+		<-copied
+	}
+}
+
+// watch checks for a stall until stop is closed
+func (w *Watchdog) watch(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	interval := w.window / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			idle := time.Since(w.last)
+			w.mu.Unlock()
+			if idle < w.window {
+				continue
+			}
+
+			report := w.Report(idle)
+			if w.OnStall != nil {
+				w.OnStall(report)
+				return
+			}
+			fmt.Fprint(w.stderr, report)
+			os.Exit(2)
+		}
+	}
+}
+
+// Blocking states a goroutine can be parked in, and what they mean
+const (
+	waitReceive   = "receive"
+	waitSend      = "send"
+	waitSelect    = "select"
+	waitWaitGroup = "waitgroup"
+	waitLock      = "lock"
+)
+
+// blocked is a goroutine parked on a synchronisation operation
+type blocked struct {
+	g     stackdump.Goroutine
+	where stackdump.Frame // the program's own frame, not the runtime's
+	kind  string
+}
+
+// classify says what g is waiting for, or "" if it is not blocked on
+// synchronisation (running, sleeping, doing I/O...)
+func classify(g stackdump.Goroutine) string {
+	switch {
+	case strings.HasPrefix(g.State, "chan receive"):
+		return waitReceive
+	case strings.HasPrefix(g.State, "chan send"):
+		return waitSend
+	case strings.HasPrefix(g.State, "select"):
+		return waitSelect
+	case g.HasFunc("sync.(*WaitGroup).Wait"):
+		return waitWaitGroup
+	case g.HasFunc("sync.(*Mutex).Lock"), g.HasFunc("sync.(*RWMutex)."):
+		return waitLock
+	}
+	return ""
+}
+
+// ownFrame returns the innermost frame that is the program's own code
+func ownFrame(g stackdump.Goroutine) stackdump.Frame {
+	for _, f := range g.Frames {
+		if !strings.HasPrefix(f.Func, "runtime.") && !strings.HasPrefix(f.Func, "sync.") &&
+			!strings.HasPrefix(f.Func, "internal/") {
+			return f
+		}
+	}
+	return g.Top()
+}
+
+// inStage reports whether f is the code of the stage: stages are expected to
+// be named after the function that runs them (or a method, or its closures)
+func inStage(f stackdump.Frame, stageName string) bool {
+	name := f.FuncName()
+	return name == stageName || strings.HasPrefix(name, stageName+".") || strings.HasSuffix(name, "."+stageName)
+}
+
+// stageIndex returns the position of the first stage f belongs to, or
+// len(stages) if it belongs to none
+func stageIndex(f stackdump.Frame, stages []string) int {
+	for i, s := range stages {
+		if inStage(f, s) {
+			return i
+		}
+	}
+	return len(stages)
+}
+
+// running reports whether any goroutine is still running stage's code
+func running(gs []stackdump.Goroutine, stageName string) bool {
+	for _, g := range gs {
+		for _, f := range g.Frames {
+			if inStage(f, stageName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Report describes the program's state after idle without progress
+func (w *Watchdog) Report(idle time.Duration) string {
+	dump := stackdump.Capture()
+	all := stackdump.Parse(dump)
+
+	w.mu.Lock()
+	stages := make([]stage, 0, len(w.order))
+	for _, name := range w.order {
+		stages = append(stages, *w.stages[name])
+	}
+	channels := append([]channel(nil), w.channels...)
+	w.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n🐕 WATCHDOG: no progress for %v - the program looks stuck\n", idle.Round(time.Millisecond))
+
+	// Stages: who made progress, when, and whether they are still around
+	var finished []string
+	stageNames := make([]string, len(stages))
+	if len(stages) > 0 {
+		fmt.Fprintln(&b, "\nStages (last progress):")
+		for i, s := range stages {
+			stageNames[i] = s.name
+			status := "running"
+			switch {
+			case s.name == StdoutStage:
+				status = "program output"
+			case !running(all, s.name):
+				status = "no longer running"
+				finished = append(finished, s.name)
+			}
+			fmt.Fprintf(&b, "   %-20s %5d beat(s), last %v ago (%s)\n",
+				s.name, s.beats, time.Since(s.last).Round(time.Millisecond), status)
+		}
+	}
+
+	// Channels: empty ones starve their receivers, full ones block senders
+	var full []string
+	if len(channels) > 0 {
+		fmt.Fprintln(&b, "\nChannels (queued/capacity):")
+		for _, c := range channels {
+			n, capacity := c.ch.Len(), c.ch.Cap()
+			note := ""
+			switch {
+			case capacity == 0:
+				note = "unbuffered"
+			case n == capacity:
+				note = "FULL"
+				full = append(full, c.name)
+			case n == 0:
+				note = "empty"
+			}
+			fmt.Fprintf(&b, "   %-20s %3d/%-3d %s\n", c.name, n, capacity, note)
+		}
+	}
+
+	// Blocked goroutines, in stage order
+	var parked []blocked
+	for _, g := range all {
+		if g.IsRuntime() || g.HasFunc("golang-concurrency-demo/watchdog.") || g.HasFunc("os/signal.") {
+			continue
+		}
+		if kind := classify(g); kind != "" {
+			parked = append(parked, blocked{g: g, where: ownFrame(g), kind: kind})
+		}
+	}
+	sort.SliceStable(parked, func(i, j int) bool {
+		return stageIndex(parked[i].where, stageNames) < stageIndex(parked[j].where, stageNames)
+	})
+	if len(parked) > 0 {
+		fmt.Fprintln(&b, "\nBlocked goroutines:")
+		for _, p := range parked {
+			fmt.Fprintf(&b, "   goroutine %-4d %-28s %s at %s:%d\n",
+				p.g.ID, "["+p.g.State+"]", p.where.FuncName(), p.where.File, p.where.Line)
+		}
+	}
+
+	fmt.Fprintln(&b, "\nDiagnosis:")
+	for _, line := range diagnose(parked, finished, full) {
+		fmt.Fprintf(&b, "   🔎 %s\n", line)
+	}
+
+	fmt.Fprintf(&b, "\nAll goroutines:\n%s\n", dump)
+	return b.String()
+}
+
+// diagnose guesses which stage is stuck and why
+func diagnose(parked []blocked, finished, full []string) []string {
+	byKind := make(map[string][]blocked)
+	for _, p := range parked {
+		byKind[p.kind] = append(byKind[p.kind], p)
+	}
+	at := func(p blocked) string {
+		return fmt.Sprintf("%s (%s:%d)", p.where.FuncName(), shortFile(p.where.File), p.where.Line)
+	}
+
+	var out []string
+	if senders := byKind[waitSend]; len(senders) > 0 {
+		why := "nothing receives from its channel any more - the next stage returned early or is stuck itself"
+		if len(full) > 0 {
+			why = fmt.Sprintf("%s is full and the stage that should drain it has stopped receiving", strings.Join(full, ", "))
+		}
+		out = append(out, fmt.Sprintf("%s is stuck sending: %s.", at(senders[0]), why))
+	}
+
+	waiters := append(byKind[waitReceive], byKind[waitSelect]...)
+	if len(waiters) > 0 {
+		names := make([]string, len(waiters))
+		for i, p := range waiters {
+			names[i] = at(p)
+		}
+		if len(finished) > 0 {
+			out = append(out, fmt.Sprintf(
+				"%s returned, but %s still waiting to receive. A stage that returns without closing its output channel leaves the range loop downstream waiting forever - check that %s closes its output (defer close(out)).",
+				strings.Join(finished, ", "), strings.Join(names, ", ")+pluralVerb(len(names)), strings.Join(finished, ", ")))
+		} else {
+			out = append(out, fmt.Sprintf(
+				"%s waiting to receive from a channel that nobody sends on or closes.",
+				strings.Join(names, ", ")+pluralVerb(len(names))))
+		}
+	}
+
+	if wgs := byKind[waitWaitGroup]; len(wgs) > 0 {
+		out = append(out, fmt.Sprintf("%s is waiting in WaitGroup.Wait: a goroutine never called Done (or Add counted too many).", at(wgs[0])))
+	}
+	if locks := byKind[waitLock]; len(locks) > 0 {
+		out = append(out, fmt.Sprintf("%s is waiting for a mutex that is never unlocked (a missing Unlock, or Lock called twice).", at(locks[0])))
+	}
+
+	if len(out) == 0 {
+		out = append(out, "No goroutine is blocked on a channel, WaitGroup or mutex: something is busy or sleeping without making progress.")
+	}
+	return out
+}
+
+// pluralVerb returns " is" or " are"
+func pluralVerb(n int) string {
+	if n == 1 {
+		return " is"
+	}
+	return " are"
+}
+
+// shortFile trims a path to its last element
+func shortFile(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
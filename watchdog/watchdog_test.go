@@ -0,0 +1,99 @@
+package watchdog_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang-concurrency-demo/watchdog"
+)
+
+const window = 100 * time.Millisecond
+
+// producer sends a few items and returns without closing out: the bug the
+// watchdog is meant to explain
+func producer(w *watchdog.Watchdog, out chan<- int) {
+	for i := 0; i < 3; i++ {
+		out <- i
+		w.Beat("producer")
+	}
+}
+
+// consumer ranges over in, so it waits forever once producer has returned
+func consumer(w *watchdog.Watchdog, in <-chan int, done chan<- struct{}) {
+	defer close(done)
+	for range in {
+		w.Beat("consumer")
+	}
+}
+
+// stalls starts w with an OnStall that hands over the report
+func stalls(w *watchdog.Watchdog) <-chan string {
+	reports := make(chan string, 1)
+	w.OnStall = func(report string) { reports <- report }
+	w.Start()
+	return reports
+}
+
+func TestStalledStageReported(t *testing.T) {
+	w := watchdog.New(window)
+	items := make(chan int)
+	w.Channel("items", items)
+	reports := stalls(w)
+	defer w.Stop()
+
+	done := make(chan struct{})
+	go consumer(w, items, done)
+	go producer(w, items)
+	defer func() {
+		close(items) // what producer should have done
+		<-done
+	}()
+
+	var report string
+	select {
+	case report = <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stall reported 5s after the pipeline stopped")
+	}
+	for _, want := range []string{
+		"no progress for",
+		"producer                 3 beat(s)",
+		"(no longer running)",
+		"consumer                 3 beat(s)",
+		"(running)",
+		"items                  0/0   unbuffered",
+		"producer returned, but consumer",
+		"defer close(out)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not mention %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestProgressNotReported(t *testing.T) {
+	w := watchdog.New(window)
+	reports := stalls(w)
+
+	// Beat well within the window for several windows running
+	for end := time.Now().Add(5 * window); time.Now().Before(end); {
+		w.Beat("busy")
+		time.Sleep(window / 5)
+	}
+	w.Stop()
+
+	select {
+	case report := <-reports:
+		t.Errorf("stall reported while the stage was making progress:\n%s", report)
+	default:
+	}
+}
+
+func TestNilWatchdog(t *testing.T) {
+	var w *watchdog.Watchdog
+	w.Start()
+	w.Beat("busy")
+	w.Channel("items", make(chan int))
+	w.Stop()
+}